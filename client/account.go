@@ -1,7 +1,9 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -12,7 +14,8 @@ import (
 // NewAccount creates a new Twitter account instance.
 //
 // Parameters:
-//   - authToken: the auth token for the account (required)
+//   - authToken: the auth token for the account (required), or a cookie export
+//     such as a cookies.txt file or a Cookie header, see addons.SetAuthCookies
 //   - ct0: the x-csrf-token for the account (optional, "" by default)
 //   - proxy: the proxy in user:pass@host:port format (optional, "" by default)
 //
@@ -27,6 +30,10 @@ import (
 //	// Create account with auth token and proxy
 //	account := twitter.NewAccount("auth_token_here", "", "user:pass@host:port")
 //
+//	// Create account from a browser cookie export
+//	data, _ := os.ReadFile("cookies.txt")
+//	account := twitter.NewAccount(string(data), "", "")
+//
 //	// Create account with all parameters
 //	account := twitter.NewAccount("auth_token_here", "csrf_token", "user:pass@host:port")
 func NewAccount(authToken, ct0, proxy string) *models.Account {
@@ -91,135 +98,104 @@ type MultiUserResponse struct {
 //	    }
 //	}
 func (t *Twitter) IsValid() (*AccountInfo, *models.ActionResponse) {
-	baseURL := fmt.Sprintf("https://api.x.com/1.1/account/multi/list.json")
+	return t.IsValidContext(context.Background())
+}
+
+// IsValidContext is like IsValid but aborts when ctx is cancelled.
+func (t *Twitter) IsValidContext(ctx context.Context) (*AccountInfo, *models.ActionResponse) {
+	baseURL := t.Config.URL(t.Config.Hosts.API, models.PathAccountMultiList)
 	// Create request config
-	reqConfig := utils.DefaultConfig()
+	reqConfig := t.Config.NewRequest()
 	reqConfig.Method = "GET"
 	reqConfig.URL = baseURL
 	reqConfig.Headers = append(reqConfig.Headers,
 		utils.HeaderPair{Key: "accept", Value: "*/*"},
 		utils.HeaderPair{Key: "authorization", Value: t.Config.Constants.BearerToken},
 		utils.HeaderPair{Key: "content-type", Value: "application/x-www-form-urlencoded"},
-		utils.HeaderPair{Key: "cookie", Value: t.Cookies.CookiesToHeader()},
-		utils.HeaderPair{Key: "origin", Value: "https://x.com"},
-		utils.HeaderPair{Key: "x-csrf-token", Value: t.Account.Ct0},
+		utils.HeaderPair{Key: "x-csrf-token", Value: t.csrfToken()},
 		utils.HeaderPair{Key: "x-twitter-active-user", Value: "yes"},
 		utils.HeaderPair{Key: "x-twitter-auth-type", Value: "OAuth2Session"},
 		utils.HeaderPair{Key: "user-agent", Value: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/133.0.0.0 Safari/537.36"},	   
 	)
 
 	// Make the request
-	bodyBytes, resp, err := utils.MakeRequest(t.Client, reqConfig)
+	bodyBytes, rateLimit, err := t.doRequest(ctx, reqConfig)
+	if errors.Is(err, models.ErrSuspended) {
+		t.logger().Error("Account is suspended")
+		return &AccountInfo{
+				Username:  t.username(),
+				Suspended: true,
+			}, &models.ActionResponse{
+				Success:   true,
+				Status:    models.StatusSuccess,
+				RateLimit: rateLimit,
+			}
+	}
 	if err != nil {
-		t.Logger.Error("%s | Failed to get account info: %v", t.Account.Username, err)
-		return nil, &models.ActionResponse{
-			Success: false,
-			Error:   err,
-			Status:  models.StatusUnknown,
-		}
+		t.logger().Error("Failed to get account info", utils.KeyError, err)
+		return nil, errorResponse(err, rateLimit)
 	}
 
-	// Update cookies
-	t.Cookies.SetCookieFromResponse(resp)
-	if newCt0, ok := t.Cookies.GetCookieValue("ct0"); ok {
-		t.Account.Ct0 = newCt0
+	var response MultiUserResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		t.logger().Error("Failed to parse account info response", utils.KeyError, err)
+		return nil, &models.ActionResponse{
+			Success:   false,
+			Error:     err,
+			Status:    models.StatusUnknown,
+			RateLimit: rateLimit,
+		}
 	}
 
-	bodyString := string(bodyBytes)
-
-	// Handle successful responses
-	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
-		var response MultiUserResponse
-		if err := json.Unmarshal(bodyBytes, &response); err != nil {
-			t.Logger.Error("%s | Failed to parse account info response: %v", t.Account.Username, err)
-			return nil, &models.ActionResponse{
-				Success: false,
-				Error:   err,
-				Status:  models.StatusUnknown,
-			}
+	// Find the current user in the response
+	var currentUser *User
+	for _, user := range response.Users {
+		if strings.EqualFold(user.ScreenName, t.username()) {
+			currentUser = &user
+			break
 		}
+	}
 
-		// Find the current user in the response
-		var currentUser *User
-		for _, user := range response.Users {
-			if strings.EqualFold(user.ScreenName, t.Account.Username) {
-				currentUser = &user
-				break
-			}
+	if currentUser == nil {
+		return nil, &models.ActionResponse{
+			Success:   false,
+			Error:     fmt.Errorf("account not found in response"),
+			Status:    models.StatusUnknown,
+			RateLimit: rateLimit,
 		}
+	}
 
-		if currentUser == nil {
-			return nil, &models.ActionResponse{
-				Success: false,
-				Error:   fmt.Errorf("account not found in response"),
-				Status:  models.StatusUnknown,
+	// Check if account is valid and not suspended
+	if !currentUser.IsAuthValid {
+		t.logger().Error("Account authentication is invalid")
+		return &AccountInfo{
+				Username:  currentUser.ScreenName,
+				Suspended: currentUser.IsSuspended,
+			}, &models.ActionResponse{
+				Success:   false,
+				Error:     models.ErrAuthFailed,
+				Status:    models.StatusAuthError,
+				RateLimit: rateLimit,
 			}
-		}
-
-		// Check if account is valid and not suspended
-		if !currentUser.IsAuthValid {
-			t.Logger.Error("%s | Account authentication is invalid", t.Account.Username)
-			return &AccountInfo{
-					Username:  currentUser.ScreenName,
-					Suspended: currentUser.IsSuspended,
-				}, &models.ActionResponse{
-					Success: false,
-					Error:   models.ErrAuthFailed,
-					Status:  models.StatusAuthError,
-				}
-		}
-
-		info := &AccountInfo{
-			Username:  currentUser.ScreenName,
-			Name:      currentUser.Name,
-			Suspended: currentUser.IsSuspended,
-			Protected: currentUser.IsProtected,
-			Verified:  currentUser.IsVerified,
-		}
+	}
 
-		if info.Suspended {
-			t.Logger.Warning("%s | Account is suspended", t.Account.Username)
-		} else {
-			t.Logger.Success("%s | Account is active and valid", t.Account.Username)
-		}
+	info := &AccountInfo{
+		Username:  currentUser.ScreenName,
+		Name:      currentUser.Name,
+		Suspended: currentUser.IsSuspended,
+		Protected: currentUser.IsProtected,
+		Verified:  currentUser.IsVerified,
+	}
 
-		return info, &models.ActionResponse{
-			Success: true,
-			Status:  models.StatusSuccess,
-		}
+	if info.Suspended {
+		t.logger().Warning("Account is suspended")
+	} else {
+		t.logger().Success("Account is active and valid")
 	}
 
-	// Handle error responses
-	switch {
-	case strings.Contains(bodyString, "this account is temporarily locked"):
-		t.Logger.Error("%s | Account is temporarily locked", t.Account.Username)
-		return nil, &models.ActionResponse{
-			Success: false,
-			Error:   models.ErrAccountLocked,
-			Status:  models.StatusLocked,
-		}
-	case strings.Contains(bodyString, "Could not authenticate you"):
-		t.Logger.Error("%s | Could not authenticate you", t.Account.Username)
-		return nil, &models.ActionResponse{
-			Success: false,
-			Error:   models.ErrAuthFailed,
-			Status:  models.StatusAuthError,
-		}
-	case strings.Contains(bodyString, "User has been suspended"):
-		t.Logger.Error("%s | Account is suspended", t.Account.Username)
-		return &AccountInfo{
-				Username:  t.Account.Username,
-				Suspended: true,
-			}, &models.ActionResponse{
-				Success: true,
-				Status:  models.StatusSuccess,
-			}
-	default:
-		t.Logger.Error("%s | Unknown response: %s", t.Account.Username, bodyString)
-		return nil, &models.ActionResponse{
-			Success: false,
-			Error:   fmt.Errorf("unknown response: %s", bodyString),
-			Status:  models.StatusUnknown,
-		}
+	return info, &models.ActionResponse{
+		Success:   true,
+		Status:    models.StatusSuccess,
+		RateLimit: rateLimit,
 	}
 }
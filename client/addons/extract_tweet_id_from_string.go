@@ -39,7 +39,7 @@ func ExtractTweetID(tweetLink string, username string, logger utils.Logger) (str
 		parts := strings.Split(tweetLink, "status/")
 		tweetID = parts[1]
 	} else {
-		logger.Error("%s | Failed to get tweet ID from your link: %s", username, tweetLink)
+		logger.Error("Failed to get tweet ID from your link", utils.KeyAccount, username, "link", tweetLink)
 		return "", fmt.Errorf("failed to get tweet ID from your link: %s", tweetLink)
 	}
 
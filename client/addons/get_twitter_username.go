@@ -1,13 +1,12 @@
 package addons
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
-	"strings"
 
 	"github.com/Tootoohk/TwitterAPI/models"
 	"github.com/Tootoohk/TwitterAPI/utils"
-	tlsClient "github.com/bogdanfinn/tls-client"
 )
 
 // GetTwitterUsername retrieves the username of a Twitter account.
@@ -24,77 +23,111 @@ import (
 //   - string: new CSRF token from response
 //   - error: any error that occurred, including account status errors
 //   - models.ActionStatus: the status of the account
-func GetTwitterUsername(httpClient tlsClient.HttpClient, cookieClient *utils.CookieClient, config *models.Config, logger utils.Logger, csrfToken string) (string, string, error, models.ActionStatus) {
+func GetTwitterUsername(httpClient utils.HttpClient, cookieClient *utils.CookieClient, config *models.Config, logger utils.Logger, csrfToken string) (string, string, error, models.ActionStatus) {
+	transport := utils.Chain(utils.NewTransport(httpClient), config.Middlewares...)
+	return GetTwitterUsernameContext(context.Background(), transport, cookieClient, config, logger, csrfToken)
+}
+
+// GetTwitterUsernameContext is like GetTwitterUsername but sends the request
+// through transport, which may carry a middleware chain, and binds every
+// attempt and the backoff between attempts to ctx.
+func GetTwitterUsernameContext(ctx context.Context, transport utils.RoundTripper, cookieClient *utils.CookieClient, config *models.Config, logger utils.Logger, csrfToken string) (string, string, error, models.ActionStatus) {
+	viewer, newCsrfToken, err, status := GetViewerContext(ctx, transport, cookieClient, config, logger, csrfToken)
+	return viewer.Username, newCsrfToken, err, status
+}
+
+// ViewerInfo identifies the account a session belongs to
+type ViewerInfo struct {
+	Username string
+	UserID   string
+}
+
+// GetViewerContext is like GetTwitterUsernameContext but also returns the
+// account's numeric user ID.
+func GetViewerContext(ctx context.Context, transport utils.RoundTripper, cookieClient *utils.CookieClient, config *models.Config, logger utils.Logger, csrfToken string) (ViewerInfo, string, error, models.ActionStatus) {
+	authToken, _ := cookieClient.GetCookieValue("auth_token")
+	redactor := config.Redactor(authToken, csrfToken)
+	logger = utils.RedactLogger(logger, redactor)
+
 	for i := 0; i < config.MaxRetries; i++ {
 		if i > 0 { // Don't sleep on first try
-			utils.RandomSleep(1, 5)
+			if err := utils.SleepDuration(ctx, config.Retry.Delay(i)); err != nil {
+				return ViewerInfo{}, "", err, models.StatusUnknown
+			}
 		}
 
-		// Build URL with query parameters
-		baseURL := "https://api.x.com/graphql/UhddhjWCl-JMqeiG4vPtvw/Viewer"
-		params := url.Values{}
-		params.Add("variables", `{"withCommunitiesMemberships":true}`)
-		params.Add("features", `{"rweb_tipjar_consumption_enabled":true,"responsive_web_graphql_exclude_directive_enabled":true,"verified_phone_label_enabled":false,"creator_subscriptions_tweet_preview_api_enabled":true,"responsive_web_graphql_skip_user_profile_image_extensions_enabled":false,"responsive_web_graphql_timeline_navigation_enabled":true}`)
-		params.Add("fieldToggles", `{"isDelegate":false,"withAuxiliaryUserLabels":false}`)
-		fullURL := baseURL + "?" + params.Encode()
-
-		// Create request config with required headers
-		reqConfig := utils.DefaultConfig()
-		reqConfig.Method = "GET"
-		reqConfig.URL = fullURL
+		// Build request
+		reqConfig, err := config.GraphQLRequest("Viewer", models.ViewerVariables{WithCommunitiesMemberships: true})
+		if err != nil {
+			return ViewerInfo{}, "", err, models.StatusUnknown
+		}
+		requestCsrfToken := csrfToken
+		if ct0, ok := cookieClient.Value(reqConfig.URL, "ct0"); ok {
+			requestCsrfToken = ct0
+		}
 		reqConfig.Headers = append(reqConfig.Headers,
 			utils.HeaderPair{Key: "authorization", Value: config.Constants.BearerToken},
-			utils.HeaderPair{Key: "cookie", Value: cookieClient.CookiesToHeader()},
-			utils.HeaderPair{Key: "origin", Value: "https://twitter.com"},
-			utils.HeaderPair{Key: "referer", Value: "https://twitter.com/"},
-			utils.HeaderPair{Key: "x-csrf-token", Value: csrfToken},
+			utils.HeaderPair{Key: "cookie", Value: cookieClient.Header(reqConfig.URL)},
+			utils.HeaderPair{Key: "referer", Value: config.WebURL("/")},
+			utils.HeaderPair{Key: "x-csrf-token", Value: requestCsrfToken},
 			utils.HeaderPair{Key: "x-twitter-active-user", Value: "no"},
 			utils.HeaderPair{Key: "user-agent", Value: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/133.0.0.0 Safari/537.36"},
 		)
 
-		bodyBytes, resp, err := utils.MakeRequest(httpClient, reqConfig)
+		bodyBytes, resp, err := utils.Send(ctx, transport, reqConfig)
 		if err != nil {
-			logger.Warning("Unknown | Failed to make get username request: %s", err.Error())
+			if ctx.Err() != nil {
+				return ViewerInfo{}, "", ctx.Err(), models.StatusUnknown
+			}
+			logger.Warning("Failed to make get username request", utils.KeyError, err)
 			continue
 		}
 
 		// Update cookies from response
-		cookieClient.SetCookieFromResponse(resp)
+		if u, err := url.Parse(reqConfig.URL); err == nil {
+			cookieClient.SetCookies(u, resp.Cookies())
+		}
 
 		// Get new CSRF token
-		newCsrfToken, ok := cookieClient.GetCookieValue("ct0")
+		newCsrfToken, ok := cookieClient.Value(config.WebURL("/"), "ct0")
 		if !ok {
-			logger.Error("Unknown | Failed to get new csrf token")
+			logger.Error("Failed to get new csrf token")
 			continue
 		}
 
+		redactor.AddSecret(newCsrfToken)
+
 		// Parse response and handle different account states
-		switch {
-		case strings.Contains(string(bodyBytes), "screen_name"):
-			var responseData getUsernameJSON
-			if err := json.Unmarshal(bodyBytes, &responseData); err != nil {
-				logger.Error("Unknown | Failed to unmarshal response: %s", err.Error())
-				continue
+		if apiErr := models.DecodeAPIError(resp.StatusCode, bodyBytes); apiErr != nil {
+			apiErr.Message = redactor.Redact(apiErr.Message)
+			status := models.StatusFromError(apiErr)
+			switch status {
+			case models.StatusLocked, models.StatusAuthError, models.StatusInvalidToken, models.StatusSuspended:
+				logger.Error("Failed to get username", utils.KeyError, apiErr)
+				return ViewerInfo{}, newCsrfToken, apiErr, status
 			}
-			username := responseData.Data.Viewer.UserResults.Result.Legacy.ScreenName
-			logger.Success("%s | Successfully got username", username)
-			return username, newCsrfToken, nil, models.StatusSuccess
-
-		case strings.Contains(string(bodyBytes), "this account is temporarily locked"):
-			logger.Error("Unknown | Account is temporarily locked!")
-			return "", newCsrfToken, models.ErrAccountLocked, models.StatusLocked
-
-		case strings.Contains(string(bodyBytes), "Could not authenticate you"):
-			logger.Error("Unknown | Could not authenticate you. Token is invalid!")
-			return "", newCsrfToken, models.ErrInvalidToken, models.StatusAuthError
+			logger.Warning("Failed to get username", utils.KeyError, apiErr)
+			continue
+		}
 
-		default:
-			logger.Error("Unknown | Unknown response: %s", string(bodyBytes))
+		var responseData getUsernameJSON
+		if err := json.Unmarshal(bodyBytes, &responseData); err != nil {
+			logger.Error("Failed to unmarshal response", utils.KeyError, err)
+			continue
 		}
+		result := responseData.Data.Viewer.UserResults.Result
+		username := result.Legacy.ScreenName
+		if username == "" {
+			logger.Error("Unknown response", "body", string(bodyBytes))
+			continue
+		}
+
+		logger.Success("Successfully got username", utils.KeyAccount, username)
+		return ViewerInfo{Username: username, UserID: result.RestID}, newCsrfToken, nil, models.StatusSuccess
 	}
 
-	logger.Error("Unknown | Unable to get twitter username after %d retries", config.MaxRetries)
-	return "", "", models.ErrUnknown, models.StatusUnknown
+	logger.Error("Unable to get twitter username", "retries", config.MaxRetries)
+	return ViewerInfo{}, "", models.ErrUnknown, models.StatusUnknown
 }
 
 // getUsernameJSON represents the JSON response structure from Twitter's GraphQL API
@@ -103,6 +136,7 @@ type getUsernameJSON struct {
 		Viewer struct {
 			UserResults struct {
 				Result struct {
+					RestID string `json:"rest_id"`
 					Legacy struct {
 						ScreenName string `json:"screen_name"`
 					} `json:"legacy"`
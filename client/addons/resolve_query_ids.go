@@ -0,0 +1,235 @@
+package addons
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twitter_utils"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// queryIDCache is the on-disk format of the query ID cache
+type queryIDCache struct {
+	FetchedAt  time.Time                                 `json:"fetchedAt"`
+	Operations map[string]twitter_utils.GraphQLOperation `json:"operations"`
+}
+
+// ResolveQueryIDs returns the GraphQL operations currently used by the X web
+// client, keyed by operation name.
+//
+// Parameters:
+//   - transport: sends the page and bundle requests (see utils.NewTransport)
+//   - config: provides Hosts.Web and the QueryIDDiscovery cache settings
+//
+// A cache younger than QueryIDDiscovery.TTL is returned without any request.
+// Otherwise the web app and its bundles are fetched and the cache rewritten;
+// if that fails, a stale cache is returned together with the error.
+//
+// Example:
+//
+//	ops, err := addons.ResolveQueryIDs(ctx, utils.NewTransport(httpClient), config)
+//	if err == nil {
+//	    addons.MergeOperations(config.Operations, ops)
+//	}
+func ResolveQueryIDs(ctx context.Context, transport utils.RoundTripper, config *models.Config) (map[string]twitter_utils.GraphQLOperation, error) {
+	cachePath := config.QueryIDDiscovery.CachePath
+	cached, err := loadQueryIDCache(cachePath)
+	if err == nil && time.Since(cached.FetchedAt) < config.QueryIDDiscovery.TTL {
+		return cached.Operations, nil
+	}
+
+	ops, err := FetchQueryIDs(ctx, transport, config)
+	if err != nil {
+		if cached != nil && len(cached.Operations) > 0 {
+			return cached.Operations, err
+		}
+		return nil, err
+	}
+
+	if cachePath != "" {
+		if err := saveQueryIDCache(cachePath, &queryIDCache{FetchedAt: time.Now(), Operations: ops}); err != nil {
+			return ops, err
+		}
+	}
+	return ops, nil
+}
+
+// FetchQueryIDs downloads the X web app and its main and api bundles and
+// parses the GraphQL operations they declare, ignoring any cache
+func FetchQueryIDs(ctx context.Context, transport utils.RoundTripper, config *models.Config) (map[string]twitter_utils.GraphQLOperation, error) {
+	pageURL := config.WebURL("/")
+	html, err := fetchText(ctx, transport, config, pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch web app: %w", err)
+	}
+
+	bundles := twitter_utils.ParseBundleURLs(html, pageURL)
+	if len(bundles) == 0 {
+		return nil, fmt.Errorf("no client bundles found in %s", pageURL)
+	}
+
+	// A bundle that fails to load is skipped; the others usually declare
+	// every operation the client needs
+	var fetchErr error
+	ops := make(map[string]twitter_utils.GraphQLOperation)
+	for _, bundle := range bundles {
+		js, err := fetchText(ctx, transport, config, bundle)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if fetchErr == nil {
+				fetchErr = fmt.Errorf("failed to fetch bundle %s: %w", bundle, err)
+			}
+			continue
+		}
+		for name, op := range twitter_utils.ParseGraphQLOperations(js) {
+			ops[name] = op
+		}
+	}
+	if len(ops) == 0 {
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return nil, fmt.Errorf("no GraphQL operations found in %d bundles", len(bundles))
+	}
+	return ops, nil
+}
+
+// MergeOperations updates registry with discovered operations:
+//   - query IDs replace the registered ones while those are still the
+//     built-in IDs, so a hot-fix loaded with LoadFile is kept
+//   - feature switches and field toggles the registry lacks are added,
+//     disabled; registered values are kept
+//   - operations the registry lacks are added, mutations as POST
+//
+// It returns the number of operations updated.
+func MergeOperations(registry *models.OperationRegistry, ops map[string]twitter_utils.GraphQLOperation) int {
+	builtin := models.DefaultOperations()
+
+	updates := make([]models.Operation, 0, len(ops))
+	for name, discovered := range ops {
+		current, known := registry.Get(name)
+		update := models.Operation{Name: name}
+
+		original, isBuiltin := builtin.Get(name)
+		if !known || (isBuiltin && current.QueryID == original.QueryID) {
+			update.QueryID = discovered.QueryID
+		}
+		if !known && discovered.OperationType == "mutation" {
+			update.Method = "POST"
+		}
+		update.Features = addFlags(current.Features, discovered.FeatureSwitches)
+		update.FieldToggles = addFlags(current.FieldToggles, discovered.FieldToggles)
+
+		if known && (update.QueryID == "" || update.QueryID == current.QueryID) && update.Features == nil && update.FieldToggles == nil {
+			continue
+		}
+		updates = append(updates, update)
+	}
+
+	registry.Set(updates...)
+	return len(updates)
+}
+
+// addFlags returns a copy of flags with the missing names added, disabled,
+// or nil if none are missing
+func addFlags(flags map[string]bool, names []string) map[string]bool {
+	var added map[string]bool
+	for _, name := range names {
+		if _, ok := flags[name]; ok {
+			continue
+		}
+		if added == nil {
+			added = make(map[string]bool, len(flags)+len(names))
+			for existing, enabled := range flags {
+				added[existing] = enabled
+			}
+		}
+		added[name] = false
+	}
+	return added
+}
+
+// QueryIDs maps operation names to query IDs, the form used by Config.QueryIDs
+func QueryIDs(ops map[string]twitter_utils.GraphQLOperation) map[string]string {
+	ids := make(map[string]string, len(ops))
+	for name, op := range ops {
+		ids[name] = op.QueryID
+	}
+	return ids
+}
+
+func fetchText(ctx context.Context, transport utils.RoundTripper, config *models.Config, rawURL string) (string, error) {
+	reqConfig := utils.RequestConfig{
+		Method:     "GET",
+		URL:        rawURL,
+		Idempotent: true,
+		Headers: []utils.HeaderPair{
+			{Key: "accept", Value: "*/*"},
+			{Key: "accept-language", Value: "en-US,en;q=0.9"},
+			{Key: "user-agent", Value: config.Constants.UserAgent},
+		},
+	}
+
+	body, resp, err := utils.Send(ctx, transport, reqConfig)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return string(body), nil
+}
+
+func loadQueryIDCache(path string) (*queryIDCache, error) {
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cache queryIDCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("failed to parse query ID cache %s: %w", path, err)
+	}
+	return &cache, nil
+}
+
+// saveQueryIDCache writes the cache through a temporary file so concurrent
+// readers never see a partial file
+func saveQueryIDCache(path string, cache *queryIDCache) error {
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create query ID cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write query ID cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write query ID cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write query ID cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write query ID cache: %w", err)
+	}
+	return nil
+}
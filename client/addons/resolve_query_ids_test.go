@@ -0,0 +1,96 @@
+package addons_test
+
+import (
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/client/addons"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twitter_utils"
+)
+
+func TestMergeOperations(t *testing.T) {
+	registry := models.DefaultOperations()
+	registry.Set(models.Operation{Name: "UnfavoriteTweet", QueryID: "hotfixUnfavoriteTweet0"})
+
+	discovered := map[string]twitter_utils.GraphQLOperation{
+		"FavoriteTweet": {
+			QueryID:       models.QueryIDLike,
+			OperationName: "FavoriteTweet",
+			OperationType: "mutation",
+		},
+		"UnfavoriteTweet": {
+			QueryID:       "discoveredUnfavorite00",
+			OperationName: "UnfavoriteTweet",
+			OperationType: "mutation",
+		},
+		"UserByScreenName": {
+			QueryID:         "xmU6X_CKVnQ5lSrCbAmJsg",
+			OperationName:   "UserByScreenName",
+			OperationType:   "query",
+			FeatureSwitches: []string{"hidden_profile_subscriptions_enabled", "rweb_profile_badges_enabled"},
+			FieldToggles:    []string{"withAuxiliaryUserLabels", "withProfileBadges"},
+		},
+		"Bookmarks": {
+			QueryID:         "QUjXply7fA7fk05FRyajEg",
+			OperationName:   "Bookmarks",
+			OperationType:   "query",
+			FeatureSwitches: []string{"graphql_timeline_v2_bookmark_timeline"},
+		},
+		"CreateBookmark": {
+			QueryID:       "aoDbu3RHznuiSkQ9aNM67Q",
+			OperationName: "CreateBookmark",
+			OperationType: "mutation",
+		},
+	}
+
+	if updated := addons.MergeOperations(registry, discovered); updated != 3 {
+		t.Errorf("MergeOperations() = %d, want 3", updated)
+	}
+
+	unfavorite, _ := registry.Get("UnfavoriteTweet")
+	if unfavorite.QueryID != "hotfixUnfavoriteTweet0" {
+		t.Errorf("hot-fixed UnfavoriteTweet query ID = %q, want it kept", unfavorite.QueryID)
+	}
+
+	user, _ := registry.Get("UserByScreenName")
+	if user.QueryID != "xmU6X_CKVnQ5lSrCbAmJsg" {
+		t.Errorf("UserByScreenName query ID = %q, want the discovered one", user.QueryID)
+	}
+	if !user.Guest || user.Method != "GET" {
+		t.Errorf("UserByScreenName = %+v, want the built-in method and guest access kept", user)
+	}
+	if enabled, ok := user.Features["hidden_profile_subscriptions_enabled"]; !ok || !enabled {
+		t.Error("built-in feature hidden_profile_subscriptions_enabled was changed")
+	}
+	if enabled, ok := user.Features["rweb_profile_badges_enabled"]; !ok || enabled {
+		t.Error("discovered feature rweb_profile_badges_enabled should be added, disabled")
+	}
+	if _, ok := user.FieldToggles["withProfileBadges"]; !ok {
+		t.Error("discovered field toggle withProfileBadges was not added")
+	}
+
+	bookmarks, ok := registry.Get("Bookmarks")
+	if !ok || bookmarks.QueryID != "QUjXply7fA7fk05FRyajEg" || bookmarks.Method != "GET" {
+		t.Errorf("Bookmarks = %+v, %v, want a new GET operation", bookmarks, ok)
+	}
+	if _, ok := bookmarks.Features["graphql_timeline_v2_bookmark_timeline"]; !ok {
+		t.Error("Bookmarks feature switches were dropped")
+	}
+	if bookmark, _ := registry.Get("CreateBookmark"); bookmark.Method != "POST" {
+		t.Errorf("CreateBookmark method = %q, want POST", bookmark.Method)
+	}
+}
+
+func TestMergeOperationsConfigQueryIDsWin(t *testing.T) {
+	config := models.NewConfig()
+	config.Operations = models.DefaultOperations()
+	config.QueryIDs = map[string]string{"FavoriteTweet": "explicitFavoriteTweet0"}
+
+	addons.MergeOperations(config.Operations, map[string]twitter_utils.GraphQLOperation{
+		"FavoriteTweet": {QueryID: "discoveredFavorite0000", OperationName: "FavoriteTweet", OperationType: "mutation"},
+	})
+
+	if op, _ := config.Operation("FavoriteTweet"); op.QueryID != "explicitFavoriteTweet0" {
+		t.Errorf("FavoriteTweet query ID = %q, want the explicit Config.QueryIDs entry", op.QueryID)
+	}
+}
@@ -1,23 +1,28 @@
 package addons
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/Tootoohk/TwitterAPI/models"
 	"github.com/Tootoohk/TwitterAPI/twitter_utils"
 	"github.com/Tootoohk/TwitterAPI/utils"
 	http "github.com/bogdanfinn/fhttp"
 )
 
 // SetAuthCookies sets authentication cookies for a Twitter client.
-// Supports both JSON cookie format and simple auth token.
-// 
+// twitterAuth is either a bare auth token or a cookie export in any format
+// accepted by utils.ParseCookies: Netscape cookies.txt, EditThisCookie or
+// Cookie-Editor JSON, a Playwright storage state or a Cookie header value.
+// Cookies for other sites are skipped; the rest are added with all their
+// attributes. A ct0 cookie is generated when the export has none.
+// Cookies are scoped to .x.com, see SetAuthCookiesForDomain.
+//
 // Parameters:
 //   - accountIndex: index of the account for logging purposes
 //   - cookieClient: client's cookie manager
-//   - twitterAuth: either JSON cookies or auth token string
+//   - twitterAuth: auth token or cookie export
 //
 // Returns:
 //   - string: auth token
@@ -28,53 +33,114 @@ import (
 //
 //	// Using auth token
 //	authToken, csrfToken, err := SetAuthCookies(0, cookieClient, "auth_token_here")
-//	
-//	// Using JSON cookies
-//	authToken, csrfToken, err := SetAuthCookies(0, cookieClient, "[{\"name\":\"auth_token\",\"value\":\"token\"}]")
+//
+//	// Using a Cookie header
+//	authToken, csrfToken, err := SetAuthCookies(0, cookieClient, "auth_token=token; ct0=csrf")
+//
+//	// Using a cookies.txt export
+//	data, _ := os.ReadFile("cookies.txt")
+//	authToken, csrfToken, err := SetAuthCookies(0, cookieClient, string(data))
 func SetAuthCookies(accountIndex int, cookieClient *utils.CookieClient, twitterAuth string) (string, string, error) {
-	csrfToken := ""
-	authToken := ""
-	var err error
-
-	// json cookies
-	if strings.Contains(twitterAuth, "[") && strings.Contains(twitterAuth, "]") {
-		jsonPart := strings.Split(strings.Split(twitterAuth, "[")[1], "]")[0]
-		var cookiesJson []map[string]string
-		if err := json.Unmarshal([]byte(jsonPart), &cookiesJson); err != nil {
-			return "", "", fmt.Errorf("%d | Failed to decode account json cookies: %v", accountIndex, err)
-		}
+	return SetAuthCookiesForDomain(accountIndex, cookieClient, twitterAuth, new(models.Config).CookieDomain())
+}
 
-		for _, cookie := range cookiesJson {
-			if name, ok := cookie["name"]; ok {
-				value := cookie["value"]
-				cookieClient.AddCookies([]http.Cookie{{Name: name, Value: value}})
-				if name == "ct0" {
-					csrfToken = value
-				}
-				if name == "auth_token" {
-					authToken = value
-				}
-			}
-		}
+// SetAuthCookiesForDomain is like SetAuthCookies but scopes the cookies to
+// domain, see models.Config.CookieDomain. This covers cookies without a
+// domain, such as a bare auth token, a Cookie header value or the generated
+// ct0, and exported x.com or twitter.com cookies, which would otherwise not
+// be sent to the configured hosts. When an export has a cookie for both
+// sites, the x.com one is kept. Scoped cookies are replaced by the ones X
+// sets and are not sent to other hosts.
+func SetAuthCookiesForDomain(accountIndex int, cookieClient *utils.CookieClient, twitterAuth string, domain string) (string, string, error) {
+	twitterAuth = strings.TrimSpace(twitterAuth)
 
 	// auth token
-	} else if len(twitterAuth) < 60 {
-		csrfToken, err = twitter_utils.GenerateCSRFToken()
+	if utils.DetectCookieFormat(twitterAuth) == utils.CookieFormatUnknown && len(twitterAuth) < 60 {
+		if twitterAuth == "" {
+			return "", "", fmt.Errorf("%d | No auth token", accountIndex)
+		}
+		csrfToken, err := twitter_utils.GenerateCSRFToken()
 		if err != nil {
 			return "", "", fmt.Errorf("%d | Failed to generate CSRF token: %v", accountIndex, err)
 		}
-		
+
 		cookieClient.AddCookies([]http.Cookie{
-			{Name: "auth_token", Value: twitterAuth},
-			{Name: "ct0", Value: csrfToken},
-			{Name: "des_opt_in", Value: "Y"},
+			{Name: "auth_token", Value: twitterAuth, Domain: domain},
+			{Name: "ct0", Value: csrfToken, Domain: domain},
+			{Name: "des_opt_in", Value: "Y", Domain: domain},
 		})
-		authToken = twitterAuth
+		return twitterAuth, csrfToken, nil
+	}
+
+	// Older account files carry the JSON cookies after other fields,
+	// e.g. "login:password:[{...}]"
+	if format := utils.DetectCookieFormat(twitterAuth); format == utils.CookieFormatUnknown || format == utils.CookieFormatHeader {
+		if start, end := strings.Index(twitterAuth, "[{"), strings.LastIndex(twitterAuth, "]"); start >= 0 && end > start {
+			twitterAuth = twitterAuth[start : end+1]
+		}
 	}
 
+	// cookie export
+	parsed, err := utils.ParseCookies(twitterAuth)
+	if err != nil {
+		return "", "", fmt.Errorf("%d | Failed to parse account cookies: %w", accountIndex, err)
+	}
+
+	// Legacy twitter.com cookies only fill in what x.com did not set
+	current := make(map[string]bool)
+	for _, cookie := range parsed {
+		if isTwitterCookie(cookie) && !isLegacyCookie(cookie) {
+			current[cookie.Name] = true
+		}
+	}
+
+	var cookies []http.Cookie
+	authToken, csrfToken := "", ""
+	for _, cookie := range parsed {
+		if !isTwitterCookie(cookie) || isLegacyCookie(cookie) && current[cookie.Name] {
+			continue
+		}
+		switch cookie.Name {
+		case "auth_token":
+			authToken = cookie.Value
+		case "ct0":
+			csrfToken = cookie.Value
+		}
+		cookie.Domain = domain
+		cookies = append(cookies, cookie)
+	}
+
+	if authToken == "" {
+		return "", "", errors.New("failed to get auth token from cookies")
+	}
 	if csrfToken == "" {
-		return "", "", errors.New("failed to get csrf token")
+		if csrfToken, err = twitter_utils.GenerateCSRFToken(); err != nil {
+			return "", "", fmt.Errorf("%d | Failed to generate CSRF token: %v", accountIndex, err)
+		}
+		cookies = append(cookies, http.Cookie{Name: "ct0", Value: csrfToken, Domain: domain})
 	}
 
+	cookieClient.AddCookies(cookies)
 	return authToken, csrfToken, nil
 }
+
+// isTwitterCookie reports whether cookie belongs to x.com or twitter.com.
+// Cookies without a domain (e.g. from a Cookie header) are kept.
+func isTwitterCookie(cookie http.Cookie) bool {
+	domain := strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+	if domain == "" {
+		return true
+	}
+	for _, site := range []string{"x.com", "twitter.com"} {
+		if domain == site || strings.HasSuffix(domain, "."+site) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLegacyCookie reports whether cookie was set by twitter.com
+func isLegacyCookie(cookie http.Cookie) bool {
+	domain := strings.ToLower(strings.TrimPrefix(cookie.Domain, "."))
+	return domain == "twitter.com" || strings.HasSuffix(domain, ".twitter.com")
+}
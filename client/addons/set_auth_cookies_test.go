@@ -0,0 +1,116 @@
+package addons_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client/addons"
+	"github.com/Tootoohk/TwitterAPI/utils"
+	http "github.com/bogdanfinn/fhttp"
+)
+
+const testAuthToken = "0123456789abcdef0123456789abcdef01234567"
+
+func TestSetAuthCookiesScopesCredentials(t *testing.T) {
+	for _, auth := range []string{testAuthToken, "auth_token=" + testAuthToken + "; lang=en"} {
+		jar := utils.NewCookieClient()
+		authToken, ct0, err := addons.SetAuthCookiesForDomain(0, jar, auth, ".x.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authToken != testAuthToken || ct0 == "" {
+			t.Errorf("SetAuthCookiesForDomain(%q) = %q, %q", auth, authToken, ct0)
+		}
+
+		for _, cookie := range jar.All() {
+			if cookie.Domain != ".x.com" {
+				t.Errorf("cookie %s has domain %q, want .x.com", cookie.Name, cookie.Domain)
+			}
+		}
+		for _, host := range []string{"https://x.com/", "https://upload.x.com/"} {
+			if header := jar.Header(host); !strings.Contains(header, "auth_token="+testAuthToken) || !strings.Contains(header, "ct0="+ct0) {
+				t.Errorf("Header(%s) = %q, want the credentials", host, header)
+			}
+		}
+		for _, host := range []string{"https://twitter.com/", "https://attacker.example/", "https://x.com.attacker.example/"} {
+			if header := jar.Header(host); header != "" {
+				t.Errorf("Header(%s) = %q, want no cookies", host, header)
+			}
+		}
+	}
+}
+
+func TestSetAuthCookiesCt0ReplacedByServer(t *testing.T) {
+	jar := utils.NewCookieClient()
+	if _, _, err := addons.SetAuthCookiesForDomain(0, jar, testAuthToken, ".x.com"); err != nil {
+		t.Fatal(err)
+	}
+	home, _ := url.Parse("https://x.com/home")
+
+	jar.SetCookies(home, []*http.Cookie{{Name: "ct0", Value: "rotated", Domain: ".x.com", Path: "/"}})
+	if header := jar.Header("https://x.com/"); strings.Count(header, "ct0=") != 1 || !strings.Contains(header, "ct0=rotated") {
+		t.Errorf("Header() = %q, want only the rotated ct0", header)
+	}
+
+	jar.SetCookies(home, []*http.Cookie{{Name: "ct0", Domain: ".x.com", Path: "/", Expires: time.Unix(1, 0)}})
+	if value, ok := jar.Value("https://x.com/", "ct0"); ok {
+		t.Errorf("ct0 = %q after the server deleted it, want none", value)
+	}
+}
+
+func TestSetAuthCookiesDefaultDomain(t *testing.T) {
+	jar := utils.NewCookieClient()
+	if _, _, err := addons.SetAuthCookies(0, jar, testAuthToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := jar.Value("https://attacker.example/", "auth_token"); ok {
+		t.Error("auth_token is sent to every host")
+	}
+	if _, ok := jar.Value("https://x.com/", "auth_token"); !ok {
+		t.Error("auth_token is not sent to x.com")
+	}
+}
+
+func TestSetAuthCookiesRescopesExports(t *testing.T) {
+	legacy := "# Netscape HTTP Cookie File\n" +
+		".twitter.com\tTRUE\t/\tTRUE\t0\tauth_token\tlegacy-token\n" +
+		".twitter.com\tTRUE\t/\tTRUE\t0\tct0\tlegacy-ct0\n" +
+		".twitter.com\tTRUE\t/\tFALSE\t0\tlang\ten\n"
+	both := legacy + ".x.com\tTRUE\t/\tTRUE\t0\tauth_token\t" + testAuthToken + "\n"
+
+	tests := []struct {
+		name, export, domain, host string
+		authToken, ct0             string
+	}{
+		{"twitter.com export", legacy, ".x.com", "https://x.com/", "legacy-token", "legacy-ct0"},
+		{"x.com preferred", both, ".x.com", "https://x.com/", testAuthToken, "legacy-ct0"},
+		{"configured host", both, "127.0.0.1", "https://127.0.0.1:8443/", testAuthToken, "legacy-ct0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jar := utils.NewCookieClient()
+			authToken, ct0, err := addons.SetAuthCookiesForDomain(0, jar, tt.export, tt.domain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if authToken != tt.authToken || ct0 != tt.ct0 {
+				t.Errorf("SetAuthCookiesForDomain() = %q, %q, want %q, %q", authToken, ct0, tt.authToken, tt.ct0)
+			}
+
+			header := jar.Header(tt.host)
+			for _, want := range []string{"auth_token=" + tt.authToken, "ct0=" + tt.ct0, "lang=en"} {
+				if !strings.Contains(header, want) {
+					t.Errorf("Header(%s) = %q, want %s", tt.host, header, want)
+				}
+			}
+			if n := strings.Count(header, "auth_token="); n != 1 {
+				t.Errorf("Header(%s) = %q, want one auth_token", tt.host, header)
+			}
+			if header := jar.Header("https://twitter.com/"); header != "" {
+				t.Errorf("Header(https://twitter.com/) = %q, want no cookies", header)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+)
+
+// API is the client surface implemented by *Twitter. Depend on it rather
+// than on the concrete type to substitute a fake (see twittertest.Fake)
+// in unit tests. Methods added to Twitter are added here as well.
+type API interface {
+	// Actions
+	Like(tweetID string) *models.ActionResponse
+	LikeContext(ctx context.Context, tweetID string) *models.ActionResponse
+	Retweet(tweetID string) *models.ActionResponse
+	RetweetContext(ctx context.Context, tweetID string) *models.ActionResponse
+	Tweet(content string, opts *TweetOptions) *models.ActionResponse
+	TweetContext(ctx context.Context, content string, opts *TweetOptions) *models.ActionResponse
+	Comment(content string, tweetID string, opts *CommentOptions) *models.ActionResponse
+	CommentContext(ctx context.Context, content string, tweetID string, opts *CommentOptions) *models.ActionResponse
+	Follow(username string) *models.ActionResponse
+	FollowContext(ctx context.Context, username string) *models.ActionResponse
+	Unfollow(userIDOrUsername string) *models.ActionResponse
+	UnfollowContext(ctx context.Context, userIDOrUsername string) *models.ActionResponse
+	VotePoll(tweetID string, answer string) *models.ActionResponse
+	VotePollContext(ctx context.Context, tweetID string, answer string) *models.ActionResponse
+	UploadMedia(mediaBase64 string) (string, error)
+	UploadMediaContext(ctx context.Context, mediaBase64 string) (string, error)
+
+	// Reads
+	IsValid() (*AccountInfo, *models.ActionResponse)
+	IsValidContext(ctx context.Context) (*AccountInfo, *models.ActionResponse)
+	GetUserInfoByUsername(username string) (*UserInfoResponse, *models.ActionResponse)
+	GetUserInfoByUsernameContext(ctx context.Context, username string) (*UserInfoResponse, *models.ActionResponse)
+	GetTweet(tweetID string) (*TweetInfo, *models.ActionResponse)
+	GetTweetContext(ctx context.Context, tweetID string) (*TweetInfo, *models.ActionResponse)
+	RateLimit(endpoint string) (models.RateLimit, bool)
+	RateLimits() map[string]models.RateLimit
+
+	// Session
+	ExportSession(opts *SessionOptions) ([]byte, error)
+	Logout(ctx context.Context) *models.ActionResponse
+	Sessions(ctx context.Context) ([]ActiveSession, *models.ActionResponse)
+	RevokeSession(ctx context.Context, sessionID string) *models.ActionResponse
+	RevokeOtherSessions(ctx context.Context) (int, *models.ActionResponse)
+
+	// Endpoints without a dedicated method
+	GraphQL(ctx context.Context, operation string, variables any, features map[string]bool, out any) *models.ActionResponse
+	REST(ctx context.Context, method, path string, form url.Values, out any) *models.ActionResponse
+}
+
+var _ API = (*Twitter)(nil)
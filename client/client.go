@@ -1,92 +1,371 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Tootoohk/TwitterAPI/client/addons"
 	"github.com/Tootoohk/TwitterAPI/models"
 	"github.com/Tootoohk/TwitterAPI/utils"
-	tlsClient "github.com/bogdanfinn/tls-client"
 )
 
-// Twitter represents a Twitter API client instance
+// Twitter represents a Twitter API client instance.
+//
+// Once NewTwitter returns, a Twitter is safe for concurrent use by multiple
+// goroutines. Requests share the cookie jar, rate limiter and credentials,
+// and a ct0 rotated by one response is sent with every later request,
+// including retries. Account fields are updated under an internal lock, so
+// read them directly only while no request is in flight. Config must not
+// be modified after NewTwitter.
 type Twitter struct {
 	Account *models.Account
-	Client  tlsClient.HttpClient
+	Client  utils.HttpClient
 	Logger  utils.Logger
 	Config  *models.Config
 	Cookies *utils.CookieClient
+
+	// Latest rate limit per endpoint, see RateLimit
+	rateLimitsMu sync.RWMutex
+	rateLimits   map[string]models.RateLimit
+	limiter      *utils.RateLimiter
+
+	// Scrubs credentials from logs and errors, nil if Config.Unredacted
+	redactor *utils.Redactor
+
+	// mu guards the Account credentials, middlewares and transport
+	mu sync.RWMutex
+
+	// Middlewares added with Use, applied after Config.Middlewares
+	middlewares []utils.Middleware
+	transport   utils.RoundTripper
+
+	// Session timestamps, see ExportSession
+	createdAt   time.Time
+	validatedAt time.Time
+
+	// Serializes session refreshes, see refreshSession
+	sessionMu          sync.Mutex
+	sessionRefreshedAt time.Time
+
+	// Guest mode, see NewGuestTwitter. The token is guarded by mu;
+	// guestMu serializes activations, see currentGuestToken.
+	guest        bool
+	guestMu      sync.Mutex
+	guestToken   string
+	guestTokenAt time.Time
 }
 
 // NewTwitter creates a new Twitter API client instance
 func NewTwitter(account *models.Account, config *models.Config) (*Twitter, error) {
-	// If no config provided, use default
-	if config == nil {
-		config = models.NewConfig()
-	}
+	return NewTwitterContext(context.Background(), account, config)
+}
 
-	twitter := &Twitter{
-		Account: account,
-		Logger:  utils.NewLogger(config.LogLevel),
-		Config:  config,
-		Cookies: utils.NewCookieClient(),
-	}
+// NewTwitterContext is like NewTwitter but runs initialization under ctx.
+// Cancelling ctx aborts the in-flight requests and any retry backoff.
+func NewTwitterContext(ctx context.Context, account *models.Account, config *models.Config) (*Twitter, error) {
+	twitter := newTwitter(account, config)
 
 	// Initialize the client
-	if err := twitter.init(); err != nil {
-		return nil, fmt.Errorf("failed to initialize Twitter client: %w", err)
+	if err := twitter.init(ctx); err != nil {
+		return nil, twitter.redactor.RedactError(fmt.Errorf("failed to initialize Twitter client: %w", err))
 	}
 
 	return twitter, nil
 }
 
+// newTwitter returns an uninitialized client, using the default config if
+// config is nil
+func newTwitter(account *models.Account, config *models.Config) *Twitter {
+	if config == nil {
+		config = models.NewConfig()
+	}
+	if hosts := config.Hosts.WithDefaults(); hosts != config.Hosts {
+		cfg := *config
+		cfg.Hosts = hosts
+		config = &cfg
+	}
+
+	return &Twitter{
+		Account:  account,
+		Logger:   newLogger(config),
+		Config:   config,
+		Cookies:  utils.NewCookieClient(),
+		limiter:  utils.NewRateLimiter(config.RateLimiter.Default, config.RateLimiter.Endpoints),
+		redactor: config.Redactor(account.AuthToken, account.Ct0),
+	}
+}
+
 // init initializes the Twitter client
-func (t *Twitter) init() error {
+func (t *Twitter) init(ctx context.Context) error {
+	if store := t.Account.CookieStore; store != nil {
+		if err := t.Cookies.Load(store); err != nil {
+			return fmt.Errorf("failed to load cookies: %w", err)
+		}
+	}
+
+	discovered := false
 	for i := 0; i < t.Config.MaxRetries; i++ {
 		if i > 0 { // Don't sleep on first try
-			utils.RandomSleep(1, 5)
+			if err := utils.SleepDuration(ctx, t.Config.Retry.Delay(i)); err != nil {
+				return err
+			}
 		}
 
 		// Create HTTP client
-		client, err := utils.CreateHttpClient(t.Account.Proxy)
+		client, err := t.newHttpClient()
 		if err != nil {
-			t.Logger.Error("Failed to create HTTP client: %s", err)
+			t.logger().Error("Failed to create HTTP client", utils.KeyError, err)
 			continue
 		}
+		t.mu.Lock()
 		t.Client = client
+		t.transport = t.newTransport()
+		t.mu.Unlock()
 
-		// Set auth cookies
-		authToken, ct0, err := addons.SetAuthCookies(i, t.Cookies, t.Account.AuthToken)
+		// Resolve current query IDs before the first GraphQL call
+		if t.Config.QueryIDDiscovery.Enabled && !discovered {
+			discovered = true
+			t.discoverQueryIDs(ctx)
+		}
+
+		// Set auth cookies. Without an auth token use the one loaded from
+		// the cookie store; a ct0 cookie it holds for the web host wins over
+		// the generated one.
+		auth := t.Account.AuthToken
+		if auth == "" {
+			auth, _ = t.Cookies.Value(t.Config.WebURL("/"), "auth_token")
+		}
+		authToken, ct0, err := addons.SetAuthCookiesForDomain(i, t.Cookies, auth, t.Config.CookieDomain())
 		if err != nil {
-			t.Logger.Error("Failed to set auth cookies: %s", err)
+			t.logger().Error("Failed to set auth cookies", utils.KeyError, err)
 			continue
 		}
+		if current, ok := t.Cookies.Value(t.Config.WebURL("/"), "ct0"); ok {
+			ct0 = current
+		}
+		t.mu.Lock()
 		t.Account.AuthToken = authToken
 		t.Account.Ct0 = ct0
+		t.mu.Unlock()
+		t.redactor.AddSecret(authToken, ct0)
 
 		// Get username and verify account
-		username, newCsrfToken, err, status := addons.GetTwitterUsername(t.Client, t.Cookies, t.Config, t.Logger, t.Account.Ct0)
+		viewer, newCsrfToken, err, status := addons.GetViewerContext(ctx, t.roundTripper(), t.Cookies, t.Config, t.baseLogger(), t.csrfToken())
 		if err != nil {
-			switch status {
-			case models.StatusLocked:
-				return fmt.Errorf("account is locked: %w", err)
-			case models.StatusAuthError:
-				return fmt.Errorf("authentication failed: %w", err)
-			case models.StatusInvalidToken:
-				return fmt.Errorf("invalid token: %w", err)
-			case models.StatusUnknown:
-				t.Logger.Error("Unknown error getting username: %s", err)
-				continue
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err := viewerError(status, err); err != nil {
+				return err
 			}
+			t.logger().Error("Unknown error getting username", utils.KeyError, err)
+			continue
 		}
 
 		// Update account info
-		t.Account.Username = username
-		t.Account.Ct0 = newCsrfToken
+		t.setViewer(viewer, newCsrfToken)
+		t.saveCookies()
 
-		t.Logger.Success("%s | Successfully initialized Twitter client and got username", username)
+		t.logger().Success("Successfully initialized Twitter client and got username")
 		return nil
 	}
 
 	return fmt.Errorf("failed to initialize after %d retries", t.Config.MaxRetries)
 }
+
+// viewerError returns the error that ends initialization for an account
+// status reported by the Viewer lookup, or nil if the lookup may be retried
+func viewerError(status models.ActionStatus, err error) error {
+	switch status {
+	case models.StatusLocked:
+		return fmt.Errorf("account is locked: %w", err)
+	case models.StatusAuthError:
+		return fmt.Errorf("authentication failed: %w", err)
+	case models.StatusInvalidToken:
+		return fmt.Errorf("invalid token: %w", err)
+	case models.StatusSuspended:
+		return fmt.Errorf("account is suspended: %w", err)
+	}
+	return nil
+}
+
+// setViewer records a successful Viewer lookup
+func (t *Twitter) setViewer(viewer addons.ViewerInfo, ct0 string) {
+	t.mu.Lock()
+	t.Account.Username = viewer.Username
+	if viewer.UserID != "" {
+		t.Account.UserID = viewer.UserID
+	}
+	t.Account.Ct0 = ct0
+	t.validatedAt = time.Now()
+	if t.createdAt.IsZero() {
+		t.createdAt = t.validatedAt
+	}
+	t.mu.Unlock()
+	t.redactor.AddSecret(ct0)
+}
+
+// newHttpClient returns the configured HttpClient or builds one for the account proxy
+func (t *Twitter) newHttpClient() (utils.HttpClient, error) {
+	if t.Config.HttpClient != nil {
+		return t.Config.HttpClient, nil
+	}
+
+	return utils.NewHttpClient(utils.HttpClientOptions{
+		Transport:          t.Config.Transport,
+		Proxy:              t.Account.Proxy,
+		Timeout:            t.Config.Timeout,
+		FollowRedirects:    t.Config.FollowRedirects,
+		InsecureSkipVerify: t.Config.InsecureSkipVerify,
+	})
+}
+
+// Use adds middlewares that wrap every subsequent request of this client.
+// They run inside the ones from Config.Middlewares, in the order given.
+func (t *Twitter) Use(middlewares ...utils.Middleware) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.middlewares = append(t.middlewares, middlewares...)
+	t.transport = t.newTransport()
+}
+
+// discoverQueryIDs merges the operations currently served by the web
+// client into Config.Operations, see addons.MergeOperations. Without a
+// registry, t.Config is replaced with a copy holding a new one so the
+// built-in operations stay untouched. A failed lookup only logs a warning
+// so the registered IDs stay in use.
+func (t *Twitter) discoverQueryIDs(ctx context.Context) {
+	ops, err := addons.ResolveQueryIDs(ctx, t.roundTripper(), t.Config)
+	if err != nil {
+		t.logger().Warning("Failed to discover query IDs", utils.KeyError, err)
+	}
+	if len(ops) == 0 {
+		return
+	}
+
+	if t.Config.Operations == nil {
+		cfg := *t.Config
+		cfg.Operations = models.DefaultOperations()
+		t.Config = &cfg
+	}
+	updated := addons.MergeOperations(t.Config.Operations, ops)
+	t.logger().Debug("Discovered query IDs", "count", len(ops), "updated", updated)
+}
+
+// newTransport chains the configured middlewares around the HTTP client.
+// The caller must hold t.mu.
+func (t *Twitter) newTransport() utils.RoundTripper {
+	middlewares := make([]utils.Middleware, 0, len(t.Config.Middlewares)+len(t.middlewares))
+	middlewares = append(middlewares, t.Config.Middlewares...)
+	middlewares = append(middlewares, t.middlewares...)
+	return utils.Chain(utils.NewTransport(t.Client), middlewares...)
+}
+
+// roundTripper returns the transport requests are sent through
+func (t *Twitter) roundTripper() utils.RoundTripper {
+	t.mu.RLock()
+	transport := t.transport
+	t.mu.RUnlock()
+	if transport != nil {
+		return transport
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.transport == nil {
+		t.transport = t.newTransport()
+	}
+	return t.transport
+}
+
+// csrfToken returns the current ct0 value
+func (t *Twitter) csrfToken() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.Account.Ct0
+}
+
+// setCsrfToken stores a rotated ct0 value
+func (t *Twitter) setCsrfToken(ct0 string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Account.Ct0 = ct0
+}
+
+// credentials returns the Cookie header for rawURL and the matching ct0
+// from a single snapshot of the jar, so a concurrent rotation cannot split
+// them. Only the most specific cookie of each name is sent.
+func (t *Twitter) credentials(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		u = &url.URL{}
+	}
+	cookies := t.Cookies.CookiesFor(u)
+	pairs := make([]string, 0, len(cookies))
+	seen := make(map[string]bool, len(cookies))
+	ct0 := ""
+	for _, cookie := range cookies {
+		if seen[cookie.Name] {
+			continue
+		}
+		seen[cookie.Name] = true
+		pairs = append(pairs, cookie.Name+"="+cookie.Value)
+		if cookie.Name == "ct0" {
+			ct0 = cookie.Value
+		}
+	}
+	if ct0 == "" {
+		ct0 = t.csrfToken()
+	}
+	return strings.Join(pairs, "; "), ct0
+}
+
+// username returns the account's screen name
+func (t *Twitter) username() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.Account.Username
+}
+
+// resolveTweetID accepts a tweet ID or URL and returns the validated tweet ID
+func (t *Twitter) resolveTweetID(idOrURL string) (string, error) {
+	idOrURL = strings.TrimSpace(idOrURL)
+	if strings.Contains(idOrURL, "twitter.com") || strings.Contains(idOrURL, "x.com") {
+		id, err := addons.ExtractTweetID(idOrURL, t.username(), t.baseLogger())
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid tweet URL: %w", models.ErrInvalidInput, err)
+		}
+		idOrURL = id
+	}
+	if err := models.ValidateTweetID(idOrURL); err != nil {
+		return "", err
+	}
+	return idOrURL, nil
+}
+
+// newLogger returns the configured Logger or builds the default one
+func newLogger(config *models.Config) utils.Logger {
+	if config.Logger != nil {
+		return config.Logger
+	}
+
+	return utils.NewLoggerWithOptions(utils.LoggerOptions{
+		Level:  config.LogLevel,
+		Format: config.LogFormat,
+		Output: config.LogOutput,
+	})
+}
+
+// baseLogger returns the client logger with credentials redacted
+func (t *Twitter) baseLogger() utils.Logger {
+	return utils.RedactLogger(t.Logger, t.redactor)
+}
+
+// logger returns the redacting client logger with the account attached
+func (t *Twitter) logger() utils.Logger {
+	return t.baseLogger().With(utils.KeyAccount, t.username())
+}
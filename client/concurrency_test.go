@@ -0,0 +1,176 @@
+package client_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+)
+
+// newClient starts a server with alice and bob and returns alice's client
+func newClient(t *testing.T, configure func(*models.Config)) (*twittertest.Server, *client.Twitter) {
+	t.Helper()
+	srv := twittertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddUser(twittertest.User{ScreenName: "bob"})
+	return srv, srv.NewClient(t, "alice", configure)
+}
+
+// exportSession returns the client's credentials, read under its lock
+func exportSession(twitter *client.Twitter) (*models.Session, error) {
+	data, err := twitter.ExportSession(nil)
+	if err != nil {
+		return nil, err
+	}
+	return models.DecodeSession(data, nil)
+}
+
+func session(t *testing.T, twitter *client.Twitter) *models.Session {
+	t.Helper()
+	s, err := exportSession(twitter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestConcurrentRequestsWithRotatingCt0(t *testing.T) {
+	// Recovery would hide a mismatched ct0 by refreshing and retrying
+	srv, twitter := newClient(t, func(c *models.Config) { c.SessionRecovery.Enabled = false })
+	srv.RotateCt0(true)
+
+	const workers = 8
+	tweets := make([]twittertest.Tweet, workers)
+	for i := range tweets {
+		tweets[i] = srv.AddTweet(twittertest.Tweet{Author: "bob", Text: fmt.Sprintf("tweet %d", i)})
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*4+1)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(tweet twittertest.Tweet) {
+			defer wg.Done()
+			if resp := twitter.Like(tweet.ID); !resp.Success {
+				errs <- fmt.Errorf("Like: %v", resp.Error)
+			}
+			if resp := twitter.Retweet(tweet.ID); !resp.Success {
+				errs <- fmt.Errorf("Retweet: %v", resp.Error)
+			}
+			if _, resp := twitter.IsValid(); !resp.Success {
+				errs <- fmt.Errorf("IsValid: %v", resp.Error)
+			}
+			if _, resp := twitter.GetUserInfoByUsername("bob"); !resp.Success {
+				errs <- fmt.Errorf("GetUserInfoByUsername: %v", resp.Error)
+			}
+		}(tweets[i])
+	}
+
+	// Credentials read while requests rotate ct0 must stay consistent
+	alice, _ := srv.GetUser("alice")
+	done := make(chan struct{})
+	inspected := make(chan struct{})
+	go func() {
+		defer close(inspected)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			s, err := exportSession(twitter)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if s.AuthToken != alice.AuthToken || s.Username != "alice" || s.Ct0 == "" {
+				errs <- fmt.Errorf("inconsistent session: user %q, auth token changed %v, ct0 %q",
+					s.Username, s.AuthToken != alice.AuthToken, s.Ct0)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+	<-inspected
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for _, r := range srv.Requests() {
+		if r.ScreenName == "alice" && r.CSRFToken == "" {
+			t.Errorf("%s sent without x-csrf-token", r.Operation)
+		}
+	}
+	for _, tweet := range tweets {
+		if got, _ := srv.GetTweet(tweet.ID); len(got.LikedBy) != 1 || len(got.RetweetedBy) != 1 {
+			t.Errorf("tweet %s: liked by %v, retweeted by %v", tweet.ID, got.LikedBy, got.RetweetedBy)
+		}
+	}
+
+	// Once the requests settle, Account.Ct0 and the jar agree and the next
+	// request carries that value
+	s := session(t, twitter)
+	if jar, _ := twitter.Cookies.Value(twitter.Config.WebURL("/"), "ct0"); jar != s.Ct0 {
+		t.Errorf("Account.Ct0 = %q, jar has %q", s.Ct0, jar)
+	}
+	last := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "last"})
+	if resp := twitter.Like(last.ID); !resp.Success {
+		t.Fatalf("Like = %+v", resp)
+	}
+	requests := srv.Requests()
+	if sent := requests[len(requests)-1].CSRFToken; sent != s.Ct0 {
+		t.Errorf("x-csrf-token = %q, want the latest ct0 %q", sent, s.Ct0)
+	}
+	if after := session(t, twitter); after.Ct0 == s.Ct0 {
+		t.Error("ct0 rotated by the last response was not stored")
+	}
+}
+
+func TestConcurrentSessionRecovery(t *testing.T) {
+	var mu sync.Mutex
+	var events []models.SessionRecoveryEvent
+	srv, twitter := newClient(t, func(c *models.Config) {
+		c.SessionRecovery.OnRecover = func(e models.SessionRecoveryEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e)
+		}
+	})
+	srv.RotateCt0(true)
+	viewerCalls := srv.Calls("Viewer")
+	srv.Inject("FavoriteTweet", twittertest.Fault{StatusCode: 403, Code: 353, Message: "csrf", Times: 1})
+
+	const workers = 8
+	var wg sync.WaitGroup
+	failures := make(chan string, workers)
+	for i := 0; i < workers; i++ {
+		tweet := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: fmt.Sprintf("tweet %d", i)})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if resp := twitter.Like(tweet.ID); !resp.Success {
+				failures <- fmt.Sprint(resp.Error)
+			}
+		}()
+	}
+	wg.Wait()
+	close(failures)
+	for failure := range failures {
+		t.Errorf("Like failed: %s", failure)
+	}
+
+	if n := srv.Calls("Viewer") - viewerCalls; n != 1 {
+		t.Errorf("Viewer calls = %d, want one refresh", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1 || events[0].Operation != "FavoriteTweet" || events[0].Err != nil {
+		t.Errorf("recovery events = %+v", events)
+	}
+}
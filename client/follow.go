@@ -1,7 +1,9 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -27,8 +29,22 @@ import (
 //	    fmt.Println("Successfully followed user")
 //	}
 func (t *Twitter) Follow(username string) *models.ActionResponse {
+	return t.FollowContext(context.Background(), username)
+}
+
+// FollowContext is like Follow but aborts when ctx is cancelled.
+func (t *Twitter) FollowContext(ctx context.Context, username string) *models.ActionResponse {
+	username, err := models.NormalizeUsername(username)
+	if err != nil {
+		return &models.ActionResponse{
+			Success: false,
+			Error:   err,
+			Status:  models.StatusUnknown,
+		}
+	}
+
 	// Build URL and request body
-	baseURL := "https://twitter.com/i/api/1.1/friendships/create.json"
+	baseURL := t.Config.URL(t.Config.Hosts.Web, models.PathFriendshipsCreate)
 	data := url.Values{}
 	data.Set("include_profile_interstitial_type", "1")
 	data.Set("include_blocking", "1")
@@ -42,85 +58,62 @@ func (t *Twitter) Follow(username string) *models.ActionResponse {
 	data.Set("screen_name", username)
 
 	// Create request config
-	reqConfig := utils.DefaultConfig()
+	reqConfig := t.Config.NewRequest()
 	reqConfig.Method = "POST"
 	reqConfig.URL = baseURL
+	reqConfig.Idempotent = true // Following an already followed user is a no-op
 	reqConfig.Body = strings.NewReader(data.Encode())
 	reqConfig.Headers = append(reqConfig.Headers,
 		utils.HeaderPair{Key: "accept", Value: "*/*"},
 		utils.HeaderPair{Key: "authorization", Value: t.Config.Constants.BearerToken},
 		utils.HeaderPair{Key: "content-type", Value: "application/x-www-form-urlencoded"},
-		utils.HeaderPair{Key: "cookie", Value: t.Cookies.CookiesToHeader()},
-		utils.HeaderPair{Key: "origin", Value: "https://twitter.com"},
-		utils.HeaderPair{Key: "referer", Value: fmt.Sprintf("https://twitter.com/%s", username)},
-		utils.HeaderPair{Key: "x-csrf-token", Value: t.Account.Ct0},
+		utils.HeaderPair{Key: "referer", Value: t.Config.WebURL("/" + username)},
+		utils.HeaderPair{Key: "x-csrf-token", Value: t.csrfToken()},
 		utils.HeaderPair{Key: "x-twitter-active-user", Value: "yes"},
 		utils.HeaderPair{Key: "x-twitter-auth-type", Value: "OAuth2Session"},
 	)
 
 	// Make the request
-	bodyBytes, resp, err := utils.MakeRequest(t.Client, reqConfig)
-	if err != nil {
-		t.Logger.Error("%s | Failed to follow %s: %v", t.Account.Username, username, err)
+	bodyBytes, rateLimit, err := t.doRequest(ctx, reqConfig)
+	if errors.Is(err, models.ErrAlreadyDone) {
+		t.logger().Success("Already requested to follow", utils.KeyUser, username)
 		return &models.ActionResponse{
-			Success: false,
-			Error:   err,
-			Status:  models.StatusUnknown,
+			Success:   true,
+			Status:    models.StatusAlreadyDone,
+			RateLimit: rateLimit,
 		}
 	}
-
-	// Update cookies
-	t.Cookies.SetCookieFromResponse(resp)
-	if newCt0, ok := t.Cookies.GetCookieValue("ct0"); ok {
-		t.Account.Ct0 = newCt0
+	if err != nil {
+		t.logger().Error("Failed to follow", utils.KeyUser, username, utils.KeyError, err)
+		return errorResponse(err, rateLimit)
 	}
 
-	bodyString := string(bodyBytes)
-
-	// Handle successful responses
-	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
-		var response models.UserResponse
-		if err := json.Unmarshal(bodyBytes, &response); err != nil {
-			t.Logger.Error("%s | Failed to parse follow response: %v", t.Account.Username, err)
-			return &models.ActionResponse{
-				Success: false,
-				Error:   err,
-				Status:  models.StatusUnknown,
-			}
-		}
-
-		// Check if we got a valid user response (contains screen_name)
-		if response.ScreenName != "" {
-			t.Logger.Success("%s | Successfully followed %s", t.Account.Username, username)
-			return &models.ActionResponse{
-				Success: true,
-				Status:  models.StatusSuccess,
-			}
+	var response models.UserResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		t.logger().Error("Failed to parse follow response", utils.KeyUser, username, utils.KeyError, err)
+		return &models.ActionResponse{
+			Success:   false,
+			Error:     err,
+			Status:    models.StatusUnknown,
+			RateLimit: rateLimit,
 		}
 	}
 
-	// Handle error responses
-	switch {
-	case strings.Contains(bodyString, "this account is temporarily locked"):
-		t.Logger.Error("%s | Account is temporarily locked", t.Account.Username)
+	// Check if we got a valid user response (contains screen_name)
+	if response.ScreenName == "" {
+		t.logger().Error("Failed to follow: unexpected response", utils.KeyUser, username)
 		return &models.ActionResponse{
-			Success: false,
-			Error:   models.ErrAccountLocked,
-			Status:  models.StatusLocked,
-		}
-	case strings.Contains(bodyString, "Could not authenticate you"):
-		t.Logger.Error("%s | Could not authenticate you", t.Account.Username)
-		return &models.ActionResponse{
-			Success: false,
-			Error:   models.ErrAuthFailed,
-			Status:  models.StatusAuthError,
-		}
-	default:
-		t.Logger.Error("%s | Unknown response: %s", t.Account.Username, bodyString)
-		return &models.ActionResponse{
-			Success: false,
-			Error:   fmt.Errorf("unknown response: %s", bodyString),
-			Status:  models.StatusUnknown,
+			Success:   false,
+			Error:     fmt.Errorf("unexpected response: %s", t.redactor.Redact(string(bodyBytes))),
+			Status:    models.StatusUnknown,
+			RateLimit: rateLimit,
 		}
 	}
+
+	t.logger().Success("Successfully followed", utils.KeyUser, username)
+	return &models.ActionResponse{
+		Success:   true,
+		Status:    models.StatusSuccess,
+		RateLimit: rateLimit,
+	}
 }
@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// TweetInfo represents a tweet returned by GetTweet
+type TweetInfo struct {
+	ID             string
+	Text           string
+	CreatedAt      string
+	AuthorID       string
+	AuthorUsername string
+	AuthorName     string
+	InReplyToID    string // Empty unless the tweet is a reply
+	LikeCount      int
+	RetweetCount   int
+	ReplyCount     int
+	QuoteCount     int
+	Liked          bool // Liked by the account; always false in guest mode
+	Retweeted      bool // Retweeted by the account; always false in guest mode
+}
+
+// tweetDetailResponse is the part of a TweetDetail response GetTweet reads
+type tweetDetailResponse struct {
+	Data struct {
+		Conversation struct {
+			Instructions []struct {
+				Entries []struct {
+					Content struct {
+						ItemContent struct {
+							TweetResults struct {
+								Result tweetResult `json:"result"`
+							} `json:"tweet_results"`
+						} `json:"itemContent"`
+					} `json:"content"`
+				} `json:"entries"`
+			} `json:"instructions"`
+		} `json:"threaded_conversation_with_injections_v2"`
+	} `json:"data"`
+}
+
+type tweetResult struct {
+	RestID string `json:"rest_id"`
+	Core   struct {
+		UserResults struct {
+			Result struct {
+				RestID string `json:"rest_id"`
+				Legacy struct {
+					Name       string `json:"name"`
+					ScreenName string `json:"screen_name"`
+				} `json:"legacy"`
+			} `json:"result"`
+		} `json:"user_results"`
+	} `json:"core"`
+	Legacy struct {
+		FullText          string `json:"full_text"`
+		CreatedAt         string `json:"created_at"`
+		InReplyToStatusID string `json:"in_reply_to_status_id_str"`
+		FavoriteCount     int    `json:"favorite_count"`
+		RetweetCount      int    `json:"retweet_count"`
+		ReplyCount        int    `json:"reply_count"`
+		QuoteCount        int    `json:"quote_count"`
+		Favorited         bool   `json:"favorited"`
+		Retweeted         bool   `json:"retweeted"`
+	} `json:"legacy"`
+
+	// Set instead of the fields above for "TweetWithVisibilityResults"
+	Tweet *tweetResult `json:"tweet"`
+}
+
+// GetTweet looks up a tweet by ID or URL. It also works in guest mode,
+// see NewGuestTwitter.
+//
+// Parameters:
+//   - tweetID: the ID or URL of the tweet
+//
+// Returns:
+//   - TweetInfo: text, author and counts of the tweet
+//   - ActionResponse: containing:
+//   - Success: true if the tweet was found
+//   - Error: any error that occurred
+//   - Status: the status of the action
+//
+// Example:
+//
+//	tweet, resp := twitter.GetTweet("https://x.com/user/status/1234567890")
+//	if resp.Success {
+//	    fmt.Printf("@%s: %s (%d likes)\n", tweet.AuthorUsername, tweet.Text, tweet.LikeCount)
+//	}
+func (t *Twitter) GetTweet(tweetID string) (*TweetInfo, *models.ActionResponse) {
+	return t.GetTweetContext(context.Background(), tweetID)
+}
+
+// GetTweetContext is like GetTweet but aborts when ctx is cancelled.
+func (t *Twitter) GetTweetContext(ctx context.Context, tweetID string) (*TweetInfo, *models.ActionResponse) {
+	tweetID, err := t.resolveTweetID(tweetID)
+	if err != nil {
+		return nil, &models.ActionResponse{
+			Success: false,
+			Error:   err,
+			Status:  models.StatusUnknown,
+		}
+	}
+
+	body, rateLimit, err := t.getTweetDetails(ctx, tweetID)
+	if err != nil {
+		t.logger().Error("Failed to get tweet", utils.KeyTweetID, tweetID, utils.KeyError, err)
+		return nil, errorResponse(err, rateLimit)
+	}
+
+	var response tweetDetailResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.logger().Error("Failed to parse tweet response", utils.KeyTweetID, tweetID, utils.KeyError, err)
+		return nil, &models.ActionResponse{
+			Success:   false,
+			Error:     err,
+			Status:    models.StatusUnknown,
+			RateLimit: rateLimit,
+		}
+	}
+
+	// The conversation holds the replies too; pick the requested tweet
+	for _, instruction := range response.Data.Conversation.Instructions {
+		for _, entry := range instruction.Entries {
+			result := entry.Content.ItemContent.TweetResults.Result
+			if result.Tweet != nil {
+				result = *result.Tweet
+			}
+			if result.RestID != tweetID {
+				continue
+			}
+
+			user := result.Core.UserResults.Result
+			t.logger().Success("Successfully got tweet", utils.KeyTweetID, tweetID)
+			return &TweetInfo{
+				ID:             result.RestID,
+				Text:           result.Legacy.FullText,
+				CreatedAt:      result.Legacy.CreatedAt,
+				AuthorID:       user.RestID,
+				AuthorUsername: user.Legacy.ScreenName,
+				AuthorName:     user.Legacy.Name,
+				InReplyToID:    result.Legacy.InReplyToStatusID,
+				LikeCount:      result.Legacy.FavoriteCount,
+				RetweetCount:   result.Legacy.RetweetCount,
+				ReplyCount:     result.Legacy.ReplyCount,
+				QuoteCount:     result.Legacy.QuoteCount,
+				Liked:          result.Legacy.Favorited,
+				Retweeted:      result.Legacy.Retweeted,
+			}, &models.ActionResponse{
+				Success:   true,
+				Status:    models.StatusSuccess,
+				RateLimit: rateLimit,
+			}
+		}
+	}
+
+	t.logger().Error("Tweet not found", utils.KeyTweetID, tweetID)
+	return nil, errorResponse(fmt.Errorf("tweet %s: %w", tweetID, models.ErrNotFound), rateLimit)
+}
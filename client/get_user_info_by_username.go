@@ -1,10 +1,9 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
-	"strings"
 
 	"github.com/Tootoohk/TwitterAPI/models"
 	"github.com/Tootoohk/TwitterAPI/utils"
@@ -61,96 +60,69 @@ type UserInfoResponse struct {
 //	    fmt.Printf("Followers: %d\n", info.Data.User.Result.Legacy.FollowersCount)
 //	}
 func (t *Twitter) GetUserInfoByUsername(username string) (*UserInfoResponse, *models.ActionResponse) {
-	// Build URL with query parameters
-	baseURL := "https://x.com/i/api/graphql/32pL5BWe9WKeSK1MoPvFQQ/UserByScreenName"
-	variables := fmt.Sprintf(`{"screen_name":"%s"}`, username)
-	features := `{"hidden_profile_subscriptions_enabled":true, "subscriptions_feature_can_gift_premium": true, "profile_label_improvements_pcf_label_in_post_enabled":true,"rweb_tipjar_consumption_enabled":true,"responsive_web_graphql_exclude_directive_enabled":true,"verified_phone_label_enabled":false,"subscriptions_verification_info_is_identity_verified_enabled":true,"subscriptions_verification_info_verified_since_enabled":true,"highlights_tweets_tab_ui_enabled":true,"responsive_web_twitter_article_notes_tab_enabled":true,"creator_subscriptions_tweet_preview_api_enabled":true,"responsive_web_graphql_skip_user_profile_image_extensions_enabled":false,"responsive_web_graphql_timeline_navigation_enabled":true}`
-	fieldToggles := `{"withAuxiliaryUserLabels":false}`
+	return t.GetUserInfoByUsernameContext(context.Background(), username)
+}
 
-	params := url.Values{}
-	params.Add("variables", variables)
-	params.Add("features", features)
-	params.Add("fieldToggles", fieldToggles)
-	fullURL := baseURL + "?" + params.Encode()
+// GetUserInfoByUsernameContext is like GetUserInfoByUsername but aborts
+// when ctx is cancelled.
+func (t *Twitter) GetUserInfoByUsernameContext(ctx context.Context, username string) (*UserInfoResponse, *models.ActionResponse) {
+	username, err := models.NormalizeUsername(username)
+	if err != nil {
+		return nil, &models.ActionResponse{
+			Success: false,
+			Error:   err,
+			Status:  models.StatusUnknown,
+		}
+	}
 
-	// Create request config
-	reqConfig := utils.DefaultConfig()
-	reqConfig.Method = "GET"
-	reqConfig.URL = fullURL
+	// Build request
+	reqConfig, err := t.Config.GraphQLRequest("UserByScreenName", models.UserByScreenNameVariables{ScreenName: username})
+	if err != nil {
+		return nil, &models.ActionResponse{
+			Success: false,
+			Error:   err,
+			Status:  models.StatusUnknown,
+		}
+	}
 	reqConfig.Headers = append(reqConfig.Headers,
 		utils.HeaderPair{Key: "accept", Value: "*/*"},
 		utils.HeaderPair{Key: "authorization", Value: t.Config.Constants.BearerToken},
 		utils.HeaderPair{Key: "content-type", Value: "application/json"},
-		utils.HeaderPair{Key: "cookie", Value: t.Cookies.CookiesToHeader()},
-		utils.HeaderPair{Key: "referer", Value: fmt.Sprintf("https://x.com/%s", username)},
-		utils.HeaderPair{Key: "x-csrf-token", Value: t.Account.Ct0},
+		utils.HeaderPair{Key: "referer", Value: t.Config.WebURL("/" + username)},
+		utils.HeaderPair{Key: "x-csrf-token", Value: t.csrfToken()},
 		utils.HeaderPair{Key: "x-twitter-active-user", Value: "no"},
 		utils.HeaderPair{Key: "x-twitter-auth-type", Value: "OAuth2Session"},
 		utils.HeaderPair{Key: "x-twitter-client-language", Value: "en"},
 	)
 
 	// Make the request
-	bodyBytes, resp, err := utils.MakeRequest(t.Client, reqConfig)
+	bodyBytes, rateLimit, err := t.doRequest(ctx, reqConfig)
 	if err != nil {
-		t.Logger.Error("%s | Failed to get user info for %s: %v", t.Account.Username, username, err)
-		return nil, &models.ActionResponse{
-			Success: false,
-			Error:   err,
-			Status:  models.StatusUnknown,
-		}
+		t.logger().Error("Failed to get user info", utils.KeyUser, username, utils.KeyError, err)
+		return nil, errorResponse(err, rateLimit)
 	}
 
-	// Update cookies
-	t.Cookies.SetCookieFromResponse(resp)
-	if newCt0, ok := t.Cookies.GetCookieValue("ct0"); ok {
-		t.Account.Ct0 = newCt0
-	}
-
-	bodyString := string(bodyBytes)
-
-	// Handle successful responses
-	if resp.StatusCode >= 200 && resp.StatusCode <= 299 && strings.Contains(bodyString, "screen_name") {
-		var response UserInfoResponse
-		if err := json.Unmarshal(bodyBytes, &response); err != nil {
-			t.Logger.Error("%s | Failed to parse user info response: %v", t.Account.Username, err)
-			return nil, &models.ActionResponse{
-				Success: false,
-				Error:   err,
-				Status:  models.StatusUnknown,
-			}
+	var response UserInfoResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		t.logger().Error("Failed to parse user info response", utils.KeyUser, username, utils.KeyError, err)
+		return nil, &models.ActionResponse{
+			Success:   false,
+			Error:     err,
+			Status:    models.StatusUnknown,
+			RateLimit: rateLimit,
 		}
+	}
 
-		if response.Data.User.Result.Legacy.ScreenName != "" {
-			t.Logger.Success("%s | Successfully got user info for %s", t.Account.Username, username)
-			return &response, &models.ActionResponse{
-				Success: true,
-				Status:  models.StatusSuccess,
-			}
-		}
+	// X answers unknown screen names with an empty data object
+	if response.Data.User.Result.Legacy.ScreenName == "" {
+		t.logger().Error("User not found", utils.KeyUser, username)
+		return nil, errorResponse(fmt.Errorf("user %s: %w", username, models.ErrNotFound), rateLimit)
 	}
 
-	// Handle error responses
-	switch {
-	case strings.Contains(bodyString, "this account is temporarily locked"):
-		t.Logger.Error("%s | Account is temporarily locked", t.Account.Username)
-		return nil, &models.ActionResponse{
-			Success: false,
-			Error:   models.ErrAccountLocked,
-			Status:  models.StatusLocked,
-		}
-	case strings.Contains(bodyString, "Could not authenticate you"):
-		t.Logger.Error("%s | Could not authenticate you", t.Account.Username)
-		return nil, &models.ActionResponse{
-			Success: false,
-			Error:   models.ErrAuthFailed,
-			Status:  models.StatusAuthError,
-		}
-	default:
-		t.Logger.Error("%s | Unknown response: %s", t.Account.Username, bodyString)
-		return nil, &models.ActionResponse{
-			Success: false,
-			Error:   fmt.Errorf("unknown response: %s", bodyString),
-			Status:  models.StatusUnknown,
-		}
+	t.logger().Success("Successfully got user info", utils.KeyUser, username)
+	return &response, &models.ActionResponse{
+		Success:   true,
+		Status:    models.StatusSuccess,
+		RateLimit: rateLimit,
 	}
 }
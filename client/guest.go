@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// guestTokenLifetime is how long a guest token is used before a new one
+// is activated. X expires them after a few hours.
+const guestTokenLifetime = 2 * time.Hour
+
+// NewGuestTwitter creates a client that reads without an account. It
+// authenticates with a guest token from guest/activate.json instead of
+// auth_token and ct0, activating a new one when X expires it.
+//
+// Only operations marked Guest in Config.Operations can be called, such as
+// GetUserInfoByUsername; the others fail with
+// models.ErrGuestNotAllowed without sending a request.
+//
+// Parameters:
+//   - proxy: the proxy in user:pass@host:port format ("" for none)
+//   - config: the client configuration, the default one if nil
+//
+// Example:
+//
+//	twitter, err := client.NewGuestTwitter("", nil)
+//	if err != nil {
+//	    return err
+//	}
+//	info, resp := twitter.GetUserInfoByUsername("elonmusk")
+func NewGuestTwitter(proxy string, config *models.Config) (*Twitter, error) {
+	return NewGuestTwitterContext(context.Background(), proxy, config)
+}
+
+// NewGuestTwitterContext is like NewGuestTwitter but activates the first
+// guest token under ctx.
+func NewGuestTwitterContext(ctx context.Context, proxy string, config *models.Config) (*Twitter, error) {
+	t := newTwitter(&models.Account{Proxy: proxy}, config)
+	t.guest = true
+
+	client, err := t.newHttpClient()
+	if err != nil {
+		return nil, t.redactor.RedactError(fmt.Errorf("failed to create guest client: %w", err))
+	}
+	t.Client = client
+	t.transport = t.newTransport()
+
+	if t.Config.QueryIDDiscovery.Enabled {
+		t.discoverQueryIDs(ctx)
+	}
+
+	if _, err := t.currentGuestToken(ctx); err != nil {
+		return nil, t.redactor.RedactError(fmt.Errorf("failed to create guest client: %w", err))
+	}
+
+	t.createdAt = time.Now()
+	t.logger().Success("Initialized guest client")
+	return t, nil
+}
+
+// guestAllowed reports whether operation may be called in guest mode
+func (t *Twitter) guestAllowed(operation string) bool {
+	if operation == models.PathGuestActivate {
+		return true
+	}
+	op, ok := t.Config.Operation(operation)
+	return ok && op.Guest
+}
+
+// currentGuestToken returns the guest token, activating one if there is
+// none yet or it is too old. Requests that find no token together share
+// one activation.
+func (t *Twitter) currentGuestToken(ctx context.Context) (string, error) {
+	if token, ok := t.freshGuestToken(); ok {
+		return token, nil
+	}
+
+	t.guestMu.Lock()
+	defer t.guestMu.Unlock()
+	if token, ok := t.freshGuestToken(); ok {
+		return token, nil
+	}
+
+	token, err := t.activateGuestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	t.mu.Lock()
+	t.guestToken, t.guestTokenAt = token, time.Now()
+	t.mu.Unlock()
+	return token, nil
+}
+
+// freshGuestToken returns the guest token unless there is none or it is
+// too old
+func (t *Twitter) freshGuestToken() (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.guestToken, t.guestToken != "" && time.Since(t.guestTokenAt) < guestTokenLifetime
+}
+
+// dropGuestToken forgets token after X rejected it, unless a concurrent
+// request already replaced it
+func (t *Twitter) dropGuestToken(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.guestToken == token {
+		t.guestToken = ""
+	}
+}
+
+// activateGuestToken gets a guest token, which stands in for the session
+// on endpoints used before login and in guest mode
+func (t *Twitter) activateGuestToken(ctx context.Context) (string, error) {
+	reqConfig := t.Config.NewRequest()
+	reqConfig.Method = "POST"
+	reqConfig.URL = t.Config.URL(t.Config.Hosts.API, models.PathGuestActivate)
+	reqConfig.Headers = append(reqConfig.Headers,
+		utils.HeaderPair{Key: "authorization", Value: t.Config.Constants.BearerToken},
+		utils.HeaderPair{Key: "referer", Value: t.Config.WebURL("/")},
+	)
+
+	bodyBytes, _, err := t.doRequest(ctx, reqConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to activate guest token: %w", err)
+	}
+
+	var response struct {
+		GuestToken string `json:"guest_token"`
+	}
+	if err := json.Unmarshal(bodyBytes, &response); err != nil || response.GuestToken == "" {
+		return "", errors.New("failed to activate guest token: no token in response")
+	}
+	t.logger().Debug("Activated guest token")
+	return response.GuestToken, nil
+}
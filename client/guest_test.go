@@ -0,0 +1,103 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+)
+
+// newGuestClient starts a server with alice and bob and returns a guest client
+func newGuestClient(t *testing.T) (*twittertest.Server, *client.Twitter) {
+	t.Helper()
+	srv := twittertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddUser(twittertest.User{ScreenName: "alice"})
+	srv.AddUser(twittertest.User{ScreenName: "bob"})
+
+	guest, err := client.NewGuestTwitter("", srv.Config())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, guest
+}
+
+func TestGuestReadsProfiles(t *testing.T) {
+	srv, guest := newGuestClient(t)
+
+	for _, name := range []string{"alice", "bob"} {
+		if info, resp := guest.GetUserInfoByUsername(name); !resp.Success || info == nil {
+			t.Errorf("GetUserInfoByUsername(%s) = %+v", name, resp)
+		}
+	}
+	if n := srv.Calls(twittertest.OpGuestActivate); n != 1 {
+		t.Errorf("guest/activate calls = %d, want one token for every request", n)
+	}
+	for _, r := range srv.Requests() {
+		if r.CSRFToken != "" || r.ScreenName != "" {
+			t.Errorf("%s sent with a session: csrf %q, user %q", r.Operation, r.CSRFToken, r.ScreenName)
+		}
+	}
+}
+
+func TestGuestNotAllowed(t *testing.T) {
+	srv, guest := newGuestClient(t)
+	tweet := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "hello"})
+
+	if _, resp := guest.GetTweet(tweet.ID); resp.Success || !errors.Is(resp.Error, models.ErrGuestNotAllowed) {
+		t.Errorf("GetTweet() = %+v, want ErrGuestNotAllowed", resp)
+	}
+	if resp := guest.Like(tweet.ID); resp.Success || !errors.Is(resp.Error, models.ErrGuestNotAllowed) {
+		t.Errorf("Like() = %+v, want ErrGuestNotAllowed", resp)
+	}
+	if n := srv.Calls("TweetDetail") + srv.Calls("FavoriteTweet"); n != 0 {
+		t.Errorf("%d requests sent for operations not allowed to guests", n)
+	}
+}
+
+func TestGuestTokenExpired(t *testing.T) {
+	srv, guest := newGuestClient(t)
+	srv.ExpireGuestTokens()
+
+	if _, resp := guest.GetUserInfoByUsername("alice"); !resp.Success {
+		t.Fatalf("GetUserInfoByUsername() = %+v, want success with a new token", resp)
+	}
+	if n := srv.Calls(twittertest.OpGuestActivate); n != 2 {
+		t.Errorf("guest/activate calls = %d, want 2", n)
+	}
+	if n := srv.Calls("UserByScreenName"); n != 2 {
+		t.Errorf("UserByScreenName calls = %d, want the rejected request sent again once", n)
+	}
+}
+
+func TestConcurrentGuestTokenActivation(t *testing.T) {
+	srv, guest := newGuestClient(t)
+	srv.ExpireGuestTokens()
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if _, resp := guest.GetUserInfoByUsername(name); !resp.Success {
+				errs <- fmt.Errorf("GetUserInfoByUsername(%s): %v", name, resp.Error)
+			}
+		}([]string{"alice", "bob"}[i%2])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// The rejected requests share the token activated after the first one
+	if n := srv.Calls(twittertest.OpGuestActivate); n != 2 {
+		t.Errorf("guest/activate calls = %d, want 2", n)
+	}
+}
@@ -0,0 +1,346 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client/addons"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twitter_utils"
+	"github.com/Tootoohk/TwitterAPI/utils"
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// maxLoginSteps bounds the onboarding flow, so a server that keeps asking
+// the same question cannot loop forever
+const maxLoginSteps = 20
+
+// LoginOptions controls Login
+type LoginOptions struct {
+	// Config is the client configuration, the default one if nil
+	Config *models.Config
+
+	// Proxy in user:pass@host:port format
+	Proxy string
+
+	// TOTPSecret is the base32 key of the authenticator app. It answers
+	// the two-factor challenge with a generated code.
+	TOTPSecret string
+
+	// Email and Phone answer the prompts X shows when it wants to confirm
+	// the account, e.g. after a login from a new location
+	Email string
+	Phone string
+
+	// Challenge answers prompts the fields above cannot, such as a code
+	// sent by email or SMS. Without it those prompts fail with
+	// models.ErrLoginChallenge.
+	Challenge func(ctx context.Context, challenge LoginChallenge) (string, error)
+}
+
+// LoginChallenge is a prompt of the login flow that needs an answer
+type LoginChallenge struct {
+	Subtask  string // Subtask ID, e.g. "LoginAcid"
+	Prompt   string // Question shown by X, e.g. "Enter your email address"
+	Hint     string // Additional explanation, if any
+	Keyboard string // Expected input as reported by X: "email", "telephone", "text", ...
+}
+
+// onboardingResponse is the state of the flow returned by every
+// onboarding/task.json call
+type onboardingResponse struct {
+	FlowToken string              `json:"flow_token"`
+	Status    string              `json:"status"`
+	Subtasks  []onboardingSubtask `json:"subtasks"`
+}
+
+type onboardingSubtask struct {
+	SubtaskID string `json:"subtask_id"`
+	EnterText *struct {
+		PrimaryText   onboardingText `json:"primary_text"`
+		SecondaryText onboardingText `json:"secondary_text"`
+		HintText      string         `json:"hint_text"`
+		KeyboardType  string         `json:"keyboard_type"`
+	} `json:"enter_text"`
+	Cta *struct {
+		PrimaryText   onboardingText `json:"primary_text"`
+		SecondaryText onboardingText `json:"secondary_text"`
+	} `json:"cta"`
+}
+
+type onboardingText struct {
+	Text string `json:"text"`
+}
+
+// Login signs in with a username (or email or phone) and password through
+// X's onboarding flow, the one the web client uses, and returns a ready
+// client. The cookie jar holds the new session, so the client can be saved
+// with ExportSession instead of logging in again.
+//
+// Two-factor codes are generated from opts.TOTPSecret, and confirmation
+// prompts are answered with opts.Email, opts.Phone or opts.Challenge.
+// Wrong credentials fail with models.ErrLoginFailed; prompts nothing could
+// answer, and captchas, fail with models.ErrLoginChallenge.
+//
+// Example:
+//
+//	twitter, err := client.Login(ctx, "alice", password, &client.LoginOptions{
+//	    TOTPSecret: os.Getenv("TWITTER_TOTP"),
+//	    Email:      "alice@example.com",
+//	})
+//	if err != nil {
+//	    return err
+//	}
+func Login(ctx context.Context, username, password string, opts *LoginOptions) (*Twitter, error) {
+	if opts == nil {
+		opts = &LoginOptions{}
+	}
+	username = strings.TrimSpace(username)
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("%w: username and password are required", models.ErrInvalidInput)
+	}
+
+	t := newTwitter(&models.Account{Proxy: opts.Proxy}, opts.Config)
+	t.redactor.AddSecret(password, opts.TOTPSecret)
+
+	client, err := t.newHttpClient()
+	if err != nil {
+		return nil, t.redactor.RedactError(fmt.Errorf("failed to log in: %w", err))
+	}
+	t.Client = client
+	t.transport = t.newTransport()
+
+	if t.Config.QueryIDDiscovery.Enabled {
+		t.discoverQueryIDs(ctx)
+	}
+
+	if err := t.runLoginFlow(ctx, username, password, opts); err != nil {
+		return nil, t.redactor.RedactError(fmt.Errorf("failed to log in: %w", err))
+	}
+
+	viewer, newCsrfToken, err, status := addons.GetViewerContext(ctx, t.roundTripper(), t.Cookies, t.Config, t.baseLogger(), t.csrfToken())
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if fatal := viewerError(status, err); fatal != nil {
+			err = fatal
+		}
+		return nil, t.redactor.RedactError(fmt.Errorf("failed to log in: %w", err))
+	}
+	t.setViewer(viewer, newCsrfToken)
+	t.createdAt = time.Now()
+
+	t.logger().Success("Logged in")
+	return t, nil
+}
+
+// runLoginFlow drives onboarding/task.json until X reports success, then
+// takes the credentials from the cookie jar
+func (t *Twitter) runLoginFlow(ctx context.Context, username, password string, opts *LoginOptions) error {
+	guestToken, err := t.activateGuestToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	flow, err := t.onboardingTask(ctx, guestToken, "login", map[string]any{
+		"input_flow_data": map[string]any{
+			"flow_context": map[string]any{
+				"debug_overrides": map[string]any{},
+				"start_location":  map[string]any{"location": "manual_link"},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for step := 0; ; step++ {
+		if flow.Status == "success" || len(flow.Subtasks) == 0 {
+			break
+		}
+		if step >= maxLoginSteps {
+			return fmt.Errorf("%w: flow did not finish after %d steps", models.ErrLoginFailed, step)
+		}
+
+		subtask := flow.Subtasks[0]
+		if subtask.SubtaskID == "LoginSuccessSubtask" {
+			break
+		}
+		t.logger().Debug("Login step", "subtask", subtask.SubtaskID)
+
+		input, err := loginInput(ctx, subtask, username, password, opts)
+		if err != nil {
+			return err
+		}
+		flow, err = t.onboardingTask(ctx, guestToken, "", map[string]any{
+			"flow_token":     flow.FlowToken,
+			"subtask_inputs": []map[string]any{input},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	webURL := t.Config.WebURL("/")
+	authToken, ok := t.Cookies.Value(webURL, "auth_token")
+	if !ok || authToken == "" {
+		return fmt.Errorf("%w: no auth_token cookie after the login flow", models.ErrLoginFailed)
+	}
+	ct0, ok := t.Cookies.Value(webURL, "ct0")
+	if !ok {
+		if ct0, err = twitter_utils.GenerateCSRFToken(); err != nil {
+			return fmt.Errorf("failed to generate CSRF token: %w", err)
+		}
+		t.Cookies.AddCookies([]http.Cookie{{Name: "ct0", Value: ct0, Domain: t.Config.CookieDomain()}})
+	}
+
+	t.mu.Lock()
+	t.Account.AuthToken = authToken
+	t.Account.Ct0 = ct0
+	t.mu.Unlock()
+	t.redactor.AddSecret(authToken, ct0)
+	return nil
+}
+
+// loginInput answers one subtask of the login flow
+func loginInput(ctx context.Context, subtask onboardingSubtask, username, password string, opts *LoginOptions) (map[string]any, error) {
+	id := subtask.SubtaskID
+	input := map[string]any{"subtask_id": id}
+
+	switch id {
+	case "LoginJsInstrumentationSubtask":
+		input["js_instrumentation"] = map[string]any{"response": "{}", "link": "next_link"}
+
+	case "LoginEnterUserIdentifierSSO":
+		input["settings_list"] = map[string]any{
+			"setting_responses": []map[string]any{{
+				"key":           "user_identifier",
+				"response_data": map[string]any{"text_data": map[string]any{"result": username}},
+			}},
+			"link": "next_link",
+		}
+
+	case "LoginEnterPassword":
+		input["enter_password"] = map[string]any{"password": password, "link": "next_link"}
+
+	case "AccountDuplicationCheck":
+		input["check_logged_in_account"] = map[string]any{"link": "AccountDuplicationCheck_false"}
+
+	case "LoginTwoFactorAuthChallenge":
+		if opts.TOTPSecret == "" {
+			code, err := askLoginChallenge(ctx, subtask, opts)
+			if err != nil {
+				return nil, err
+			}
+			input["enter_text"] = map[string]any{"text": code, "link": "next_link"}
+			break
+		}
+		code, err := twitter_utils.GenerateTOTP(opts.TOTPSecret, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrInvalidInput, err)
+		}
+		input["enter_text"] = map[string]any{"text": code, "link": "next_link"}
+
+	case "LoginAcid", "LoginEnterAlternateIdentifierSubtask":
+		answer := ""
+		if subtask.EnterText != nil {
+			switch keyboard := strings.ToLower(subtask.EnterText.KeyboardType); {
+			case keyboard == "email":
+				answer = opts.Email
+			case strings.Contains(keyboard, "phone"):
+				answer = opts.Phone
+			}
+		}
+		if answer == "" {
+			var err error
+			if answer, err = askLoginChallenge(ctx, subtask, opts); err != nil {
+				return nil, err
+			}
+		}
+		input["enter_text"] = map[string]any{"text": answer, "link": "next_link"}
+
+	case "DenyLoginSubtask":
+		message := "login denied"
+		if subtask.Cta != nil && subtask.Cta.SecondaryText.Text != "" {
+			message = subtask.Cta.SecondaryText.Text
+		}
+		return nil, fmt.Errorf("%w: %s", models.ErrLoginFailed, message)
+
+	default:
+		// ArkoseLogin (captcha) and anything else this client cannot answer
+		return nil, fmt.Errorf("%w: unsupported login step %s", models.ErrLoginChallenge, id)
+	}
+
+	return input, nil
+}
+
+// askLoginChallenge passes a prompt to LoginOptions.Challenge
+func askLoginChallenge(ctx context.Context, subtask onboardingSubtask, opts *LoginOptions) (string, error) {
+	challenge := LoginChallenge{Subtask: subtask.SubtaskID}
+	if text := subtask.EnterText; text != nil {
+		challenge.Prompt = text.PrimaryText.Text
+		challenge.Hint = text.SecondaryText.Text
+		if challenge.Hint == "" {
+			challenge.Hint = text.HintText
+		}
+		challenge.Keyboard = text.KeyboardType
+	}
+
+	if opts.Challenge == nil {
+		return "", fmt.Errorf("%w: %s asks %q", models.ErrLoginChallenge, challenge.Subtask, challenge.Prompt)
+	}
+	answer, err := opts.Challenge(ctx, challenge)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", models.ErrLoginChallenge, challenge.Subtask, err)
+	}
+	if strings.TrimSpace(answer) == "" {
+		return "", fmt.Errorf("%w: empty answer to %s", models.ErrLoginChallenge, challenge.Subtask)
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// onboardingTask sends one onboarding/task.json request. flowName starts
+// a new flow; later steps pass the flow token in body instead.
+func (t *Twitter) onboardingTask(ctx context.Context, guestToken, flowName string, body map[string]any) (*onboardingResponse, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode login step: %w", err)
+	}
+
+	reqConfig := t.Config.NewRequest()
+	reqConfig.Method = "POST"
+	reqConfig.URL = t.Config.URL(t.Config.Hosts.API, models.PathOnboardingTask)
+	if flowName != "" {
+		reqConfig.URL += "?flow_name=" + flowName
+	}
+	reqConfig.Body = strings.NewReader(string(encoded))
+	reqConfig.Headers = append(reqConfig.Headers,
+		utils.HeaderPair{Key: "authorization", Value: t.Config.Constants.BearerToken},
+		utils.HeaderPair{Key: "content-type", Value: "application/json"},
+		utils.HeaderPair{Key: "referer", Value: t.Config.WebURL("/i/flow/login")},
+		utils.HeaderPair{Key: "x-guest-token", Value: guestToken},
+		utils.HeaderPair{Key: "x-twitter-active-user", Value: "yes"},
+		utils.HeaderPair{Key: "x-twitter-client-language", Value: "en"},
+	)
+	if ct0, ok := t.Cookies.Value(reqConfig.URL, "ct0"); ok {
+		reqConfig.Headers = append(reqConfig.Headers, utils.HeaderPair{Key: "x-csrf-token", Value: ct0})
+	}
+
+	bodyBytes, _, err := t.doRequest(ctx, reqConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var flow onboardingResponse
+	if err := json.Unmarshal(bodyBytes, &flow); err != nil {
+		return nil, fmt.Errorf("failed to parse login step: %w", err)
+	}
+	if flow.FlowToken == "" && flow.Status != "success" {
+		return nil, fmt.Errorf("%w: response has no flow token", models.ErrLoginFailed)
+	}
+	return &flow, nil
+}
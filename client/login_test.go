@@ -0,0 +1,136 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twitter_utils"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+const (
+	testPassword   = "correct horse battery staple"
+	testTOTPSecret = "JBSWY3DPEHPK3PXP"
+)
+
+// newLoginServer starts a server with carol, who can log in with
+// testPassword, and returns it with the login options for it
+func newLoginServer(t *testing.T, carol twittertest.User) (*twittertest.Server, *client.LoginOptions) {
+	t.Helper()
+	srv := twittertest.NewServer()
+	t.Cleanup(srv.Close)
+	carol.ScreenName = "carol"
+	carol.Password = testPassword
+	srv.AddUser(carol)
+	return srv, &client.LoginOptions{Config: srv.Config()}
+}
+
+func TestLoginPassword(t *testing.T) {
+	srv, opts := newLoginServer(t, twittertest.User{})
+
+	twitter, err := client.Login(context.Background(), "carol", testPassword, opts)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	s := session(t, twitter)
+	if s.Username != "carol" || s.AuthToken == "" || s.Ct0 == "" {
+		t.Errorf("session = %+v, want carol's credentials", s)
+	}
+	if n := srv.Calls(twittertest.OpOnboardingTask); n != 5 {
+		t.Errorf("onboarding calls = %d, want 5", n)
+	}
+
+	// The session works for authenticated requests
+	if _, resp := twitter.IsValid(); !resp.Success {
+		t.Errorf("IsValid() error = %v, want a valid session", resp.Error)
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	_, opts := newLoginServer(t, twittertest.User{})
+
+	_, err := client.Login(context.Background(), "carol", "wrong password", opts)
+	if !errors.Is(err, models.ErrLoginFailed) {
+		t.Fatalf("Login() error = %v, want ErrLoginFailed", err)
+	}
+	if strings.Contains(err.Error(), "wrong password") {
+		t.Errorf("Login() error %q contains the password", err)
+	}
+}
+
+func TestLoginTOTP(t *testing.T) {
+	_, opts := newLoginServer(t, twittertest.User{TOTPSecret: testTOTPSecret})
+	opts.TOTPSecret = testTOTPSecret
+
+	twitter, err := client.Login(context.Background(), "carol", testPassword, opts)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if s := session(t, twitter); s.Username != "carol" {
+		t.Errorf("session username = %q, want carol", s.Username)
+	}
+}
+
+func TestLoginTOTPChallenge(t *testing.T) {
+	_, opts := newLoginServer(t, twittertest.User{TOTPSecret: testTOTPSecret, Email: "carol@example.com", ConfirmEmail: true})
+	opts.Email = "carol@example.com"
+
+	var asked []client.LoginChallenge
+	opts.Challenge = func(ctx context.Context, challenge client.LoginChallenge) (string, error) {
+		asked = append(asked, challenge)
+		return twitter_utils.GenerateTOTP(testTOTPSecret, time.Now())
+	}
+
+	if _, err := client.Login(context.Background(), "carol", testPassword, opts); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if len(asked) != 1 || asked[0].Subtask != "LoginTwoFactorAuthChallenge" || asked[0].Prompt == "" {
+		t.Errorf("challenges = %+v, want only the two-factor prompt", asked)
+	}
+}
+
+func TestLoginWrongCode(t *testing.T) {
+	_, opts := newLoginServer(t, twittertest.User{TOTPSecret: testTOTPSecret})
+	opts.Challenge = func(ctx context.Context, challenge client.LoginChallenge) (string, error) {
+		return "not-a-code", nil
+	}
+
+	if _, err := client.Login(context.Background(), "carol", testPassword, opts); !errors.Is(err, models.ErrLoginFailed) {
+		t.Errorf("Login() error = %v, want ErrLoginFailed", err)
+	}
+}
+
+func TestLoginChallengeWithoutHandler(t *testing.T) {
+	_, opts := newLoginServer(t, twittertest.User{TOTPSecret: testTOTPSecret})
+
+	if _, err := client.Login(context.Background(), "carol", testPassword, opts); !errors.Is(err, models.ErrLoginChallenge) {
+		t.Errorf("Login() error = %v, want ErrLoginChallenge", err)
+	}
+}
+
+func TestLoginUnknownSubtask(t *testing.T) {
+	_, opts := newLoginServer(t, twittertest.User{})
+
+	// Replace the password step with a captcha the client cannot solve
+	opts.Config.Middlewares = append(opts.Config.Middlewares, func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			resp, err := next.RoundTrip(ctx, req)
+			if err == nil {
+				resp.Body = bytes.ReplaceAll(resp.Body, []byte(`"subtask_id":"LoginEnterPassword"`), []byte(`"subtask_id":"ArkoseLogin"`))
+			}
+			return resp, err
+		})
+	})
+
+	_, err := client.Login(context.Background(), "carol", testPassword, opts)
+	if !errors.Is(err, models.ErrLoginChallenge) || !strings.Contains(err.Error(), "ArkoseLogin") {
+		t.Errorf("Login() error = %v, want ErrLoginChallenge naming ArkoseLogin", err)
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// ActiveSession is a login of the account, as listed under
+// Settings → Security and account access → Sessions
+type ActiveSession struct {
+	ID          string // Hashed token, pass to RevokeSession
+	Current     bool   // The session this client is signed in with
+	Application string // Client the session was created from, e.g. "Twitter Web App"
+	Location    string
+	CreatedAt   string
+	LastSeenAt  string
+}
+
+// sessionsResponse is the body of the sessions list endpoint
+type sessionsResponse struct {
+	Sessions []struct {
+		HashedToken     string `json:"hashed_token"`
+		IsCurrent       bool   `json:"is_current"`
+		ApplicationName string `json:"client_application_name"`
+		Location        string `json:"location"`
+		CreatedAt       string `json:"created_at"`
+		LastSeenAt      string `json:"last_seen_at"`
+	} `json:"sessions"`
+}
+
+// Logout ends the session on X and clears the credentials held by the
+// client: the cookie jar, Account.AuthToken and Account.Ct0. The emptied
+// jar is saved to Account.CookieStore so the session is not restored on
+// the next start. Requests made afterwards fail with StatusAuthError.
+//
+// If X already rejects the session, the credentials are cleared as well
+// and the response reports StatusAlreadyDone.
+//
+// Example:
+//
+//	twitter.RevokeOtherSessions(ctx)
+//	if resp := twitter.Logout(ctx); !resp.Success {
+//	    fmt.Println("logout failed:", resp.Error)
+//	}
+func (t *Twitter) Logout(ctx context.Context) *models.ActionResponse {
+	form := url.Values{}
+	form.Set("redirectAfterLogout", t.Config.WebURL("/account/switch"))
+	resp := t.REST(ctx, "POST", t.Config.URL(t.Config.Hosts.API, models.PathAccountLogout), form, nil)
+
+	if !resp.Success {
+		if !errors.Is(resp.Error, models.ErrAuthFailed) && !errors.Is(resp.Error, models.ErrInvalidToken) {
+			t.logger().Error("Failed to log out", utils.KeyError, resp.Error)
+			return resp
+		}
+		resp = &models.ActionResponse{
+			Success:   true,
+			Status:    models.StatusAlreadyDone,
+			RateLimit: resp.RateLimit,
+		}
+	}
+
+	t.clearCredentials()
+	t.logger().Success("Logged out")
+	return resp
+}
+
+// clearCredentials forgets the session after Logout
+func (t *Twitter) clearCredentials() {
+	t.Cookies.Clear()
+	t.mu.Lock()
+	t.Account.AuthToken = ""
+	t.Account.Ct0 = ""
+	t.Account.Cookies = nil
+	t.mu.Unlock()
+	t.saveCookies()
+}
+
+// Sessions lists the active sessions of the account, including the one
+// the client is signed in with.
+//
+// Example:
+//
+//	sessions, resp := twitter.Sessions(ctx)
+//	for _, session := range sessions {
+//	    fmt.Println(session.Application, session.Location, session.LastSeenAt)
+//	}
+func (t *Twitter) Sessions(ctx context.Context) ([]ActiveSession, *models.ActionResponse) {
+	var response sessionsResponse
+	resp := t.REST(ctx, "GET", models.PathSessionsList, nil, &response)
+	if !resp.Success {
+		return nil, resp
+	}
+
+	sessions := make([]ActiveSession, 0, len(response.Sessions))
+	for _, session := range response.Sessions {
+		sessions = append(sessions, ActiveSession{
+			ID:          session.HashedToken,
+			Current:     session.IsCurrent,
+			Application: session.ApplicationName,
+			Location:    session.Location,
+			CreatedAt:   session.CreatedAt,
+			LastSeenAt:  session.LastSeenAt,
+		})
+	}
+	return sessions, resp
+}
+
+// RevokeSession signs out the session with the given ActiveSession.ID.
+// Use Logout to end the session the client is signed in with.
+func (t *Twitter) RevokeSession(ctx context.Context, sessionID string) *models.ActionResponse {
+	if sessionID == "" {
+		return &models.ActionResponse{
+			Success: false,
+			Error:   fmt.Errorf("%w: empty session ID", models.ErrInvalidInput),
+			Status:  models.StatusUnknown,
+		}
+	}
+
+	form := url.Values{}
+	form.Set("hashed_token", sessionID)
+	resp := t.REST(ctx, "POST", models.PathSessionsRevoke, form, nil)
+	if resp.Success {
+		t.logger().Success("Revoked session")
+	}
+	return resp
+}
+
+// RevokeOtherSessions signs out every session of the account except the
+// one the client is signed in with. It stops at the first failure and
+// returns the number of sessions revoked.
+func (t *Twitter) RevokeOtherSessions(ctx context.Context) (int, *models.ActionResponse) {
+	sessions, resp := t.Sessions(ctx)
+	if !resp.Success {
+		return 0, resp
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.Current {
+			continue
+		}
+		if resp = t.RevokeSession(ctx, session.ID); !resp.Success {
+			return revoked, resp
+		}
+		revoked++
+	}
+
+	t.logger().Success("Revoked other sessions", "count", revoked)
+	return revoked, &models.ActionResponse{
+		Success:   true,
+		Status:    models.StatusSuccess,
+		RateLimit: resp.RateLimit,
+	}
+}
@@ -0,0 +1,157 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+)
+
+// newSessionsClient returns alice's client with two more logins, phone
+// and laptop, and the SessionRecovery events of the client
+func newSessionsClient(t *testing.T) (*twittertest.Server, *client.Twitter, func() []models.SessionRecoveryEvent) {
+	t.Helper()
+	var events func() []models.SessionRecoveryEvent
+	srv, twitter := newClient(t, func(c *models.Config) { events = recoveryEvents(c) })
+	srv.UpdateUser("alice", func(u *twittertest.User) { u.Sessions = []string{"phone", "laptop"} })
+	return srv, twitter, events
+}
+
+func TestLogout(t *testing.T) {
+	srv, twitter, _ := newSessionsClient(t)
+
+	if resp := twitter.Logout(context.Background()); !resp.Success || resp.Status != models.StatusSuccess {
+		t.Fatalf("Logout() = %+v", resp)
+	}
+	if alice, _ := srv.GetUser("alice"); alice.AuthToken != "" || len(alice.Sessions) != 2 {
+		t.Errorf("alice = %+v, want only the client's login ended", alice)
+	}
+	if account := twitter.Account; account.AuthToken != "" || account.Ct0 != "" || len(twitter.Cookies.All()) != 0 {
+		t.Errorf("credentials kept after Logout(): %+v, cookies %v", account, twitter.Cookies.All())
+	}
+	if _, resp := twitter.IsValid(); resp.Success || resp.Status != models.StatusAuthError {
+		t.Errorf("IsValid() after Logout() = %+v, want StatusAuthError", resp)
+	}
+}
+
+func TestLogoutRejected(t *testing.T) {
+	srv, twitter, events := newSessionsClient(t)
+	srv.Inject(twittertest.OpAccountLogout, twittertest.Fault{StatusCode: 401, Code: 32, Message: "Could not authenticate you.", Times: 1})
+	viewerCalls := srv.Calls("Viewer")
+
+	if resp := twitter.Logout(context.Background()); !resp.Success || resp.Status != models.StatusAlreadyDone {
+		t.Fatalf("Logout() = %+v, want StatusAlreadyDone", resp)
+	}
+	if twitter.Account.AuthToken != "" {
+		t.Errorf("auth token %q kept after a rejected Logout()", twitter.Account.AuthToken)
+	}
+	if n := srv.Calls("Viewer") - viewerCalls; n != 0 {
+		t.Errorf("Viewer calls = %d, want no session refresh", n)
+	}
+	if got := events(); len(got) != 0 {
+		t.Errorf("recovery events = %+v, want none", got)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	_, twitter, _ := newSessionsClient(t)
+
+	sessions, resp := twitter.Sessions(context.Background())
+	if !resp.Success {
+		t.Fatalf("Sessions() error = %v", resp.Error)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("Sessions() = %+v, want 3 logins", sessions)
+	}
+	current := 0
+	for _, session := range sessions {
+		if session.ID == "" || session.Application == "" {
+			t.Errorf("session %+v has no ID or application", session)
+		}
+		if session.Current {
+			current++
+		}
+	}
+	if current != 1 || !sessions[0].Current {
+		t.Errorf("Sessions() = %+v, want the client's login marked current", sessions)
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	srv, twitter, _ := newSessionsClient(t)
+	ctx := context.Background()
+	sessions, resp := twitter.Sessions(ctx)
+	if !resp.Success {
+		t.Fatalf("Sessions() error = %v", resp.Error)
+	}
+
+	if resp := twitter.RevokeSession(ctx, sessions[1].ID); !resp.Success {
+		t.Fatalf("RevokeSession() = %+v", resp)
+	}
+	if alice, _ := srv.GetUser("alice"); len(alice.Sessions) != 1 || alice.Sessions[0] != "laptop" {
+		t.Errorf("Sessions = %q, want [laptop]", alice.Sessions)
+	}
+
+	if resp := twitter.RevokeSession(ctx, sessions[1].ID); resp.Success || resp.Status != models.StatusNotFound {
+		t.Errorf("RevokeSession(revoked) = %+v, want StatusNotFound", resp)
+	}
+	calls := srv.Calls(twittertest.OpSessionsRevoke)
+	if resp := twitter.RevokeSession(ctx, ""); resp.Success || !errors.Is(resp.Error, models.ErrInvalidInput) {
+		t.Errorf("RevokeSession(\"\") = %+v, want ErrInvalidInput", resp)
+	}
+	if n := srv.Calls(twittertest.OpSessionsRevoke); n != calls {
+		t.Error("RevokeSession(\"\") sent a request")
+	}
+}
+
+func TestRevokeSessionRejected(t *testing.T) {
+	srv, twitter, events := newSessionsClient(t)
+	ctx := context.Background()
+	sessions, resp := twitter.Sessions(ctx)
+	if !resp.Success {
+		t.Fatalf("Sessions() error = %v", resp.Error)
+	}
+	srv.Inject(twittertest.OpSessionsRevoke, twittertest.Fault{StatusCode: 403, Code: 353, Message: "csrf", Times: 1})
+	viewerCalls := srv.Calls("Viewer")
+
+	if resp := twitter.RevokeSession(ctx, sessions[1].ID); resp.Success || !errors.Is(resp.Error, models.ErrBadCSRF) {
+		t.Errorf("RevokeSession() = %+v, want ErrBadCSRF", resp)
+	}
+	if n := srv.Calls("Viewer") - viewerCalls; n != 0 {
+		t.Errorf("Viewer calls = %d, want no session refresh", n)
+	}
+	if got := events(); len(got) != 0 {
+		t.Errorf("recovery events = %+v, want none", got)
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	srv, twitter, _ := newSessionsClient(t)
+
+	revoked, resp := twitter.RevokeOtherSessions(context.Background())
+	if !resp.Success || revoked != 2 {
+		t.Fatalf("RevokeOtherSessions() = %d, %+v, want 2 revoked", revoked, resp)
+	}
+	if alice, _ := srv.GetUser("alice"); alice.AuthToken == "" || len(alice.Sessions) != 0 {
+		t.Errorf("alice = %+v, want only the client's login left", alice)
+	}
+	if _, resp := twitter.IsValid(); !resp.Success {
+		t.Errorf("IsValid() after RevokeOtherSessions() = %+v, want the client signed in", resp)
+	}
+}
+
+func TestRevokeOtherSessionsStopsAtFailure(t *testing.T) {
+	srv, twitter, _ := newSessionsClient(t)
+	srv.Inject(twittertest.OpSessionsRevoke, twittertest.Fault{StatusCode: 500, Message: "Internal error"})
+
+	revoked, resp := twitter.RevokeOtherSessions(context.Background())
+	if resp.Success || revoked != 0 {
+		t.Errorf("RevokeOtherSessions() = %d, %+v, want a failure before any revoke", revoked, resp)
+	}
+	if alice, _ := srv.GetUser("alice"); len(alice.Sessions) != 2 {
+		t.Errorf("Sessions = %q, want both logins kept", alice.Sessions)
+	}
+}
@@ -0,0 +1,115 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// tracer records which middlewares a request passed, in order
+type tracer struct {
+	mu    sync.Mutex
+	trace []string
+}
+
+func (tr *tracer) middleware(name string) utils.Middleware {
+	return func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			tr.add(name + ">" + req.Operation)
+			resp, err := next.RoundTrip(ctx, req)
+			tr.add("<" + name)
+			return resp, err
+		})
+	}
+}
+
+func (tr *tracer) add(entry string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.trace = append(tr.trace, entry)
+}
+
+func (tr *tracer) reset() string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	trace := strings.Join(tr.trace, " ")
+	tr.trace = nil
+	return trace
+}
+
+func TestUseOrder(t *testing.T) {
+	tr := &tracer{}
+	_, twitter := newClient(t, func(c *models.Config) {
+		c.Middlewares = []utils.Middleware{tr.middleware("config")}
+	})
+	if trace := tr.reset(); !strings.Contains(trace, "config>Viewer") {
+		t.Errorf("trace = %q, want Config.Middlewares to wrap initialization", trace)
+	}
+
+	twitter.Use(tr.middleware("first"), tr.middleware("second"))
+	twitter.Use(tr.middleware("third"))
+	if _, resp := twitter.GetUserInfoByUsername("bob"); !resp.Success {
+		t.Fatalf("GetUserInfoByUsername() = %+v", resp)
+	}
+	want := "config>UserByScreenName first>UserByScreenName second>UserByScreenName third>UserByScreenName <third <second <first <config"
+	if trace := tr.reset(); trace != want {
+		t.Errorf("trace = %q, want %q", trace, want)
+	}
+}
+
+func TestUseShortCircuit(t *testing.T) {
+	srv, twitter := newClient(t, nil)
+	twitter.Use(func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			if req.Operation != "UserByScreenName" {
+				return next.RoundTrip(ctx, req)
+			}
+			return &utils.Response{StatusCode: 200, Body: []byte(`{"errors":[{"code":50,"message":"User not found."}]}`)}, nil
+		})
+	})
+
+	if _, resp := twitter.GetUserInfoByUsername("bob"); resp.Success || resp.Status != models.StatusNotFound {
+		t.Errorf("GetUserInfoByUsername() = %+v, want the middleware's StatusNotFound", resp)
+	}
+	if n := srv.Calls("UserByScreenName"); n != 0 {
+		t.Errorf("UserByScreenName calls = %d, want the request answered by the middleware", n)
+	}
+	if _, resp := twitter.IsValid(); !resp.Success {
+		t.Errorf("IsValid() = %+v, want other requests passed through", resp)
+	}
+}
+
+func TestUseErrors(t *testing.T) {
+	srv, twitter := newClient(t, func(c *models.Config) { c.Retry.MaxAttempts = 1 })
+	errBlocked := errors.New("blocked by policy")
+	var seen error
+	twitter.Use(
+		func(next utils.RoundTripper) utils.RoundTripper {
+			return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+				resp, err := next.RoundTrip(ctx, req)
+				seen = err
+				return resp, err
+			})
+		},
+		func(next utils.RoundTripper) utils.RoundTripper {
+			return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+				return nil, errBlocked
+			})
+		},
+	)
+
+	if _, resp := twitter.GetUserInfoByUsername("bob"); resp.Success || !errors.Is(resp.Error, errBlocked) {
+		t.Errorf("GetUserInfoByUsername() = %+v, want the middleware's error", resp)
+	}
+	if !errors.Is(seen, errBlocked) {
+		t.Errorf("outer middleware saw %v, want the inner one's error", seen)
+	}
+	if n := srv.Calls("UserByScreenName"); n != 0 {
+		t.Errorf("UserByScreenName calls = %d, want none", n)
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// RateLimit returns the latest rate limit X reported for an endpoint.
+// Endpoints are keyed by GraphQL operation name (e.g. "FavoriteTweet")
+// or REST path (e.g. models.PathFriendshipsCreate).
+//
+// Example:
+//
+//	if limit, ok := twitter.RateLimit("FavoriteTweet"); ok && limit.Exhausted() {
+//	    time.Sleep(limit.ResetIn())
+//	}
+func (t *Twitter) RateLimit(endpoint string) (models.RateLimit, bool) {
+	t.rateLimitsMu.RLock()
+	defer t.rateLimitsMu.RUnlock()
+
+	rateLimit, ok := t.rateLimits[endpoint]
+	return rateLimit, ok
+}
+
+// RateLimits returns a copy of the latest rate limits for every endpoint called so far
+func (t *Twitter) RateLimits() map[string]models.RateLimit {
+	t.rateLimitsMu.RLock()
+	defer t.rateLimitsMu.RUnlock()
+
+	rateLimits := make(map[string]models.RateLimit, len(t.rateLimits))
+	for endpoint, rateLimit := range t.rateLimits {
+		rateLimits[endpoint] = rateLimit
+	}
+	return rateLimits
+}
+
+// recordRateLimit stores the snapshot taken from a response
+func (t *Twitter) recordRateLimit(rateLimit *models.RateLimit) {
+	if rateLimit == nil {
+		return
+	}
+
+	t.rateLimitsMu.Lock()
+	defer t.rateLimitsMu.Unlock()
+
+	if t.rateLimits == nil {
+		t.rateLimits = make(map[string]models.RateLimit)
+	}
+	t.rateLimits[rateLimit.Endpoint] = *rateLimit
+}
+
+// waitRateLimit blocks until the client-side limiter lets a request to
+// endpoint through, or fails if that would take longer than MaxWait
+func (t *Twitter) waitRateLimit(ctx context.Context, endpoint string) error {
+	cfg := t.Config.RateLimiter
+	if !cfg.Enabled || t.limiter == nil {
+		return nil
+	}
+
+	for {
+		wait := t.limiter.Reserve(endpoint)
+		if wait <= 0 {
+			return nil
+		}
+		if cfg.MaxWait > 0 && wait > cfg.MaxWait {
+			return fmt.Errorf("%w: %s is limited for another %s", models.ErrRateLimited, endpoint, wait.Round(time.Second))
+		}
+
+		t.logger().Info("Rate limit reached, waiting", utils.KeyOperation, endpoint, "wait", wait)
+		if cfg.OnWait != nil {
+			cfg.OnWait(endpoint, wait)
+		}
+		if err := utils.SleepDuration(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// observeRateLimit feeds a response back into the client-side limiter.
+// After a rate-limit rejection the endpoint is paused until X's reset time.
+// Daily limits (models.ErrDailyLimit) are per action rather than per
+// endpoint window and do not pause it.
+func (t *Twitter) observeRateLimit(endpoint string, rateLimit *models.RateLimit, err error) {
+	if t.limiter == nil {
+		return
+	}
+
+	if rateLimit != nil && rateLimit.HasRemaining {
+		t.limiter.Observe(endpoint, rateLimit.Limit, rateLimit.Remaining, rateLimit.Reset)
+	}
+
+	if errors.Is(err, models.ErrRateLimited) {
+		until := time.Now().Add(t.Config.RateLimiter.Backoff)
+		if rateLimit != nil && rateLimit.Reset.After(time.Now()) {
+			until = rateLimit.Reset
+		}
+		t.logger().Warning("Rate limited", utils.KeyOperation, endpoint, "until", until.Format("15:04:05"))
+		t.limiter.Block(endpoint, until)
+	}
+}
@@ -0,0 +1,107 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+)
+
+func TestDailyLimitIsFinal(t *testing.T) {
+	for _, code := range []int{185, 344} {
+		srv, twitter := newClient(t, func(c *models.Config) {
+			c.Retry.BaseDelay = time.Millisecond
+			c.RateLimiter.MaxWait = time.Millisecond
+		})
+		srv.Inject("CreateTweet", twittertest.Fault{StatusCode: 429, Code: code, Message: "daily limit", Times: 1})
+
+		resp := twitter.Tweet("first", nil)
+		if resp.Success || !errors.Is(resp.Error, models.ErrDailyLimit) || resp.Status != models.StatusRateLimited {
+			t.Fatalf("code %d: Tweet() = %+v, want ErrDailyLimit", code, resp)
+		}
+		if errors.Is(resp.Error, models.ErrRateLimited) {
+			t.Errorf("code %d: daily limit also matches ErrRateLimited", code)
+		}
+		if n := srv.Calls("CreateTweet"); n != 1 {
+			t.Errorf("code %d: CreateTweet calls = %d, want no retries", code, n)
+		}
+
+		// The endpoint is not paused by the limiter
+		if resp := twitter.Tweet("second", nil); !resp.Success {
+			t.Errorf("code %d: Tweet() after the daily limit = %+v, want the request sent", code, resp)
+		}
+	}
+}
+
+// newRateLimitedClient returns alice's client with a tweet to like, after
+// a Like rejected with fault
+func newRateLimitedClient(t *testing.T, fault twittertest.Fault, configure func(*models.Config)) (*twittertest.Server, *client.Twitter, twittertest.Tweet) {
+	t.Helper()
+	srv, twitter := newClient(t, func(c *models.Config) {
+		c.Retry.MaxAttempts = 1
+		if configure != nil {
+			configure(c)
+		}
+	})
+	tweet := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "hello"})
+	srv.Inject("FavoriteTweet", fault)
+	if resp := twitter.Like(tweet.ID); resp.Status != models.StatusRateLimited {
+		t.Fatalf("Like() = %+v, want StatusRateLimited", resp)
+	}
+	return srv, twitter, tweet
+}
+
+func TestRateLimitMaxWait(t *testing.T) {
+	waits := 0
+	srv, twitter, tweet := newRateLimitedClient(t, twittertest.RateLimited(time.Now().Add(time.Hour)), func(c *models.Config) {
+		c.RateLimiter.MaxWait = time.Second
+		c.RateLimiter.OnWait = func(string, time.Duration) { waits++ }
+	})
+
+	resp := twitter.Like(tweet.ID)
+	if resp.Success || !errors.Is(resp.Error, models.ErrRateLimited) {
+		t.Errorf("Like() = %+v, want ErrRateLimited without waiting for the reset", resp)
+	}
+	if n := srv.Calls("FavoriteTweet"); n != 1 || waits != 0 {
+		t.Errorf("FavoriteTweet calls = %d, waits = %d, want the request held back at once", n, waits)
+	}
+}
+
+func TestRateLimitBackoffWithoutReset(t *testing.T) {
+	var waits []time.Duration
+	srv, twitter, tweet := newRateLimitedClient(t, twittertest.Fault{StatusCode: 429, Code: 88, Message: "Rate limit exceeded.", Times: 1}, func(c *models.Config) {
+		c.RateLimiter.Backoff = 50 * time.Millisecond
+		c.RateLimiter.OnWait = func(_ string, wait time.Duration) { waits = append(waits, wait) }
+	})
+
+	if resp := twitter.Like(tweet.ID); !resp.Success {
+		t.Fatalf("Like() = %+v, want success after the backoff", resp)
+	}
+	if len(waits) != 1 || waits[0] <= 0 || waits[0] > 50*time.Millisecond {
+		t.Errorf("waits = %v, want one of up to the 50ms backoff", waits)
+	}
+	if n := srv.Calls("FavoriteTweet"); n != 2 {
+		t.Errorf("FavoriteTweet calls = %d, want 2", n)
+	}
+}
+
+func TestRateLimitWaitCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv, twitter, tweet := newRateLimitedClient(t, twittertest.RateLimited(time.Now().Add(time.Hour)), func(c *models.Config) {
+		c.RateLimiter.MaxWait = 0
+		c.RateLimiter.OnWait = func(string, time.Duration) { cancel() }
+	})
+
+	resp := twitter.LikeContext(ctx, tweet.ID)
+	if resp.Success || !errors.Is(resp.Error, context.Canceled) {
+		t.Errorf("LikeContext() = %+v, want context.Canceled", resp)
+	}
+	if n := srv.Calls("FavoriteTweet"); n != 1 {
+		t.Errorf("FavoriteTweet calls = %d, want the cancelled request not sent", n)
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// GraphQL calls a GraphQL operation that has no dedicated method and
+// decodes the response into out (unless out is nil). The request goes
+// through the same pipeline as every other call: auth headers, cookie and
+// ct0 rotation, rate limiting, retries and error decoding.
+//
+// Parameters:
+//   - operation: name of an operation in Config.Operations, or one listed
+//     in Config.QueryIDs (sent as GET to the web host)
+//   - variables: encoded as the operation's "variables" JSON, nil for none
+//   - features: added to the operation's default feature flags, nil for none
+//   - out: pointer to the result type, e.g. *json.RawMessage
+//
+// Example:
+//
+//	config.Operations.Set(models.Operation{Name: "Bookmarks", QueryID: "...", Method: "GET"})
+//	var out struct {
+//	    Data json.RawMessage `json:"data"`
+//	}
+//	resp := twitter.GraphQL(ctx, "Bookmarks", map[string]any{"count": 20}, nil, &out)
+//	if !resp.Success {
+//	    fmt.Println(resp.Error)
+//	}
+func (t *Twitter) GraphQL(ctx context.Context, operation string, variables any, features map[string]bool, out any) *models.ActionResponse {
+	op, ok := t.Config.Operation(operation)
+	if !ok {
+		return &models.ActionResponse{
+			Success: false,
+			Error:   fmt.Errorf("unknown GraphQL operation %s", operation),
+			Status:  models.StatusUnknown,
+		}
+	}
+
+	reqConfig, err := t.Config.NewGraphQLRequest(op.WithFeatures(features), variables)
+	if err != nil {
+		return &models.ActionResponse{
+			Success: false,
+			Error:   err,
+			Status:  models.StatusUnknown,
+		}
+	}
+	if reqConfig.Method != "GET" {
+		reqConfig.Headers = append(reqConfig.Headers,
+			utils.HeaderPair{Key: "content-type", Value: "application/json"})
+	}
+
+	return t.call(ctx, reqConfig, out)
+}
+
+// REST calls a v1.1-style endpoint that has no dedicated method and
+// decodes the JSON response into out (unless out is nil). Like GraphQL,
+// it shares the client's auth, rate limiting, retries and error decoding.
+//
+// Parameters:
+//   - method: HTTP method, e.g. "GET" or "POST"
+//   - path: path on Config.Hosts.Web such as "/i/api/1.1/blocks/create.json",
+//     or an absolute URL on one of the other Config.Hosts. URLs on other
+//     hosts are rejected so the session never leaves them.
+//   - form: added to the query string for GET, sent as a form body otherwise
+//   - out: pointer to the result type
+//
+// Example:
+//
+//	form := url.Values{"user_id": {userID}}
+//	var user struct {
+//	    ScreenName string `json:"screen_name"`
+//	}
+//	resp := twitter.REST(ctx, "POST", "/i/api/1.1/blocks/create.json", form, &user)
+func (t *Twitter) REST(ctx context.Context, method, path string, form url.Values, out any) *models.ActionResponse {
+	endpoint, err := url.Parse(path)
+	if err == nil && !endpoint.IsAbs() {
+		endpoint, err = url.Parse(t.Config.WebURL(path))
+	}
+	if err != nil {
+		return &models.ActionResponse{
+			Success: false,
+			Error:   fmt.Errorf("%w: invalid URL: %w", models.ErrInvalidInput, err),
+			Status:  models.StatusUnknown,
+		}
+	}
+	if !t.Config.Hosts.Contains(endpoint) {
+		return &models.ActionResponse{
+			Success: false,
+			Error:   fmt.Errorf("%w: %s is not one of Config.Hosts", models.ErrInvalidInput, endpoint.Host),
+			Status:  models.StatusUnknown,
+		}
+	}
+
+	reqConfig := t.Config.NewRequest()
+	reqConfig.Method = strings.ToUpper(method)
+	if reqConfig.Method == "GET" {
+		if len(form) > 0 {
+			query := endpoint.Query()
+			for key, values := range form {
+				query[key] = append(query[key], values...)
+			}
+			endpoint.RawQuery = query.Encode()
+		}
+	} else {
+		reqConfig.Body = strings.NewReader(form.Encode())
+		reqConfig.Headers = append(reqConfig.Headers,
+			utils.HeaderPair{Key: "content-type", Value: "application/x-www-form-urlencoded"})
+	}
+	reqConfig.URL = endpoint.String()
+
+	return t.call(ctx, reqConfig, out)
+}
+
+// call adds the session headers to reqConfig, sends it and decodes the
+// response into out
+func (t *Twitter) call(ctx context.Context, reqConfig utils.RequestConfig, out any) *models.ActionResponse {
+	if reqConfig.Operation == "" {
+		reqConfig.Operation = utils.OperationFromURL(reqConfig.URL)
+	}
+	reqConfig.Headers = append(reqConfig.Headers,
+		utils.HeaderPair{Key: "accept", Value: "*/*"},
+		utils.HeaderPair{Key: "authorization", Value: t.Config.Constants.BearerToken},
+		utils.HeaderPair{Key: "referer", Value: t.Config.WebURL("/")},
+		utils.HeaderPair{Key: "x-csrf-token", Value: t.csrfToken()},
+		utils.HeaderPair{Key: "x-twitter-active-user", Value: "yes"},
+		utils.HeaderPair{Key: "x-twitter-auth-type", Value: "OAuth2Session"},
+		utils.HeaderPair{Key: "x-twitter-client-language", Value: "en"},
+	)
+
+	bodyBytes, rateLimit, err := t.doRequest(ctx, reqConfig)
+	if err != nil {
+		t.logger().Error("Request failed", utils.KeyOperation, reqConfig.Operation, utils.KeyError, err)
+		return errorResponse(err, rateLimit)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(bodyBytes, out); err != nil {
+			return &models.ActionResponse{
+				Success:   false,
+				Error:     fmt.Errorf("failed to parse response: %w", err),
+				Status:    models.StatusUnknown,
+				RateLimit: rateLimit,
+			}
+		}
+	}
+
+	return &models.ActionResponse{
+		Success:   true,
+		Status:    models.StatusSuccess,
+		RateLimit: rateLimit,
+	}
+}
@@ -0,0 +1,117 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// recorder is a middleware answering every request with {} and recording
+// its URL and cookie header
+type recorder struct {
+	mu      sync.Mutex
+	urls    []string
+	cookies []string
+}
+
+func (r *recorder) middleware(next utils.RoundTripper) utils.RoundTripper {
+	return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+		r.mu.Lock()
+		r.urls = append(r.urls, req.URL)
+		r.cookies = append(r.cookies, req.GetHeader("cookie"))
+		r.mu.Unlock()
+		return &utils.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: []byte(`{}`)}, nil
+	})
+}
+
+func (r *recorder) sent() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.urls...)
+}
+
+func TestRESTRejectsForeignHosts(t *testing.T) {
+	_, twitter := newClient(t, nil)
+	rec := &recorder{}
+	twitter.Use(rec.middleware)
+
+	for _, endpoint := range []string{
+		"https://attacker.example/collect",
+		"https://x.com.attacker.example/i/api/1.1/account/settings.json",
+		"ftp://x.com/",
+	} {
+		resp := twitter.REST(context.Background(), "GET", endpoint, nil, nil)
+		if resp.Success || !errors.Is(resp.Error, models.ErrInvalidInput) {
+			t.Errorf("REST(%s) = %+v, want ErrInvalidInput", endpoint, resp)
+		}
+	}
+	if sent := rec.sent(); len(sent) != 0 {
+		t.Errorf("requests sent to foreign hosts: %q", sent)
+	}
+}
+
+func TestRESTConfiguredHosts(t *testing.T) {
+	srv, twitter := newClient(t, nil)
+	rec := &recorder{}
+	twitter.Use(rec.middleware)
+	hosts := srv.Config().Hosts
+	alice, _ := srv.GetUser("alice")
+
+	for _, endpoint := range []string{"/i/api/1.1/account/settings.json", hosts.API + "/1.1/account/settings.json"} {
+		if resp := twitter.REST(context.Background(), "GET", endpoint, nil, nil); !resp.Success {
+			t.Errorf("REST(%s) error = %v", endpoint, resp.Error)
+		}
+	}
+
+	for i, cookie := range rec.cookies {
+		if !strings.Contains(cookie, "auth_token="+alice.AuthToken) || strings.Count(cookie, "ct0=") != 1 {
+			t.Errorf("request %d cookie header = %q, want the session cookies", i, cookie)
+		}
+	}
+
+	sent := rec.sent()
+	want := []string{hosts.Web + "/i/api/1.1/account/settings.json", hosts.API + "/1.1/account/settings.json"}
+	if len(sent) != len(want) {
+		t.Fatalf("sent %q, want %q", sent, want)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Errorf("sent %q, want %q", sent[i], want[i])
+		}
+	}
+}
+
+func TestRESTMergesQuery(t *testing.T) {
+	_, twitter := newClient(t, nil)
+	rec := &recorder{}
+	twitter.Use(rec.middleware)
+
+	form := url.Values{"cursor": {"a&b=c"}, "count": {"20"}}
+	resp := twitter.REST(context.Background(), "GET", "/i/api/1.1/account/sessions/list.json?include_ext=1", form, nil)
+	if !resp.Success {
+		t.Fatalf("REST() error = %v", resp.Error)
+	}
+
+	sent := rec.sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(sent))
+	}
+	if strings.Count(sent[0], "?") != 1 {
+		t.Errorf("URL %s has more than one query string", sent[0])
+	}
+	u, err := url.Parse(sent[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("include_ext") != "1" || query.Get("cursor") != "a&b=c" || query.Get("count") != "20" || len(query) != 3 {
+		t.Errorf("query = %v, want include_ext, cursor and count", query)
+	}
+}
@@ -0,0 +1,77 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// echoCredentials answers operation with body, after replacing %s with
+// the credentials the request was sent with
+func echoCredentials(operation string, statusCode int, body string) utils.Middleware {
+	return func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			if req.Operation != operation {
+				return next.RoundTrip(ctx, req)
+			}
+			cookie, csrf := req.GetHeader("cookie"), req.GetHeader("x-csrf-token")
+			return &utils.Response{StatusCode: statusCode, Body: []byte(fmt.Sprintf(body, cookie, csrf, req.GetHeader("authorization")))}, nil
+		})
+	}
+}
+
+func TestCredentialsRedacted(t *testing.T) {
+	var logs bytes.Buffer
+	srv, twitter := newClient(t, func(c *models.Config) {
+		c.Retry.MaxAttempts = 1
+		c.Logger = utils.NewLoggerWithOptions(utils.LoggerOptions{Level: utils.LogLevelDebug, Format: utils.LogFormatJSON, Output: &logs})
+	})
+	tweet := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "hello"})
+	twitter.Use(
+		echoCredentials("CreateRetweet", 200, `{"data":{"create_retweet":{}},"debug":{"cookie":"%s","x-csrf-token":"%s","authorization":"%s"}}`),
+		echoCredentials("FavoriteTweet", 500, "Cookie: %s\nx-csrf-token: %s\nAuthorization: %s"),
+	)
+
+	retweet := twitter.Retweet(tweet.ID)
+	if retweet.Success || !strings.Contains(retweet.Error.Error(), "unexpected response:") {
+		t.Fatalf("Retweet() = %+v, want an unexpected response", retweet)
+	}
+	like := twitter.Like(tweet.ID)
+	if like.Success {
+		t.Fatalf("Like() = %+v, want a failure", like)
+	}
+
+	s := session(t, twitter)
+	bearer := strings.TrimPrefix(twitter.Config.Constants.BearerToken, "Bearer ")
+	for name, text := range map[string]string{
+		"Retweet() error": retweet.Error.Error(),
+		"Like() error":    like.Error.Error(),
+		"logs":            logs.String(),
+	} {
+		for _, secret := range []string{s.AuthToken, s.Ct0, bearer} {
+			if strings.Contains(text, secret) {
+				t.Errorf("%s contains a credential: %s", name, text)
+			}
+		}
+		if !strings.Contains(text, utils.Redacted) {
+			t.Errorf("%s = %s, want the credentials replaced with %s", name, text, utils.Redacted)
+		}
+	}
+}
+
+func TestCredentialsUnredacted(t *testing.T) {
+	srv, twitter := newClient(t, func(c *models.Config) { c.Unredacted = true })
+	tweet := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "hello"})
+	twitter.Use(echoCredentials("CreateRetweet", 200, `{"data":{"create_retweet":{}},"debug":{"cookie":"%s","x-csrf-token":"%s","authorization":"%s"}}`))
+
+	resp := twitter.Retweet(tweet.ID)
+	if s := session(t, twitter); resp.Success || !strings.Contains(resp.Error.Error(), s.Ct0) {
+		t.Errorf("Retweet() = %+v, want the response as sent with Unredacted", resp)
+	}
+}
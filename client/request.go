@@ -0,0 +1,193 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// doRequest sends the request through the retry policy. Every attempt
+// waits for the client-side rate limiter, updates the cookie jar and ct0
+// from the response, records the endpoint's rate limit and decodes any
+// error reported by X.
+//
+// The returned error is either a transport error or a *models.APIError
+// wrapping one of the models.Err* sentinels. The body and rate limit are
+// returned alongside API errors so callers can still inspect them.
+func (t *Twitter) doRequest(ctx context.Context, reqConfig utils.RequestConfig) ([]byte, *models.RateLimit, error) {
+	if reqConfig.Operation == "" {
+		reqConfig.Operation = utils.OperationFromURL(reqConfig.URL)
+	}
+
+	// Buffer the body so every attempt can send it again
+	var body []byte
+	if reqConfig.Body != nil {
+		var err error
+		if body, err = io.ReadAll(reqConfig.Body); err != nil {
+			return nil, nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	guestMode := t.guest && reqConfig.Operation != models.PathGuestActivate
+	if guestMode && !t.guestAllowed(reqConfig.Operation) {
+		return nil, nil, fmt.Errorf("%s: %w", reqConfig.Operation, models.ErrGuestNotAllowed)
+	}
+	guestRefreshed := false
+	sessionRefreshed := false
+
+	policy := t.Config.Retry
+	for attempt := 1; ; attempt++ {
+		if err := t.waitRateLimit(ctx, reqConfig.Operation); err != nil {
+			return nil, nil, err
+		}
+
+		guestToken := ""
+		if guestMode {
+			var err error
+			if guestToken, err = t.currentGuestToken(ctx); err != nil {
+				return nil, nil, err
+			}
+			reqConfig.SetHeader("x-guest-token", guestToken)
+		}
+
+		if body != nil {
+			reqConfig.Body = bytes.NewReader(body)
+		}
+		sent := time.Now()
+		bodyBytes, rateLimit, statusCode, err := t.send(ctx, reqConfig)
+		if err == nil {
+			return bodyBytes, rateLimit, nil
+		}
+
+		// An expired guest token is replaced once, without using up an attempt
+		if guestMode && !guestRefreshed && errors.Is(err, models.ErrInvalidToken) {
+			guestRefreshed = true
+			t.dropGuestToken(guestToken)
+			t.logger().Debug("Guest token rejected, activating a new one", utils.KeyOperation, reqConfig.Operation)
+			attempt--
+			continue
+		}
+
+		// A rotated ct0 or a session X stopped accepting is refreshed
+		// once, also without using up an attempt
+		if !sessionRefreshed && sessionRejected(err) && t.canRecoverSession(reqConfig) {
+			sessionRefreshed = true
+			if t.refreshSession(ctx, reqConfig.Operation, err, sent) == nil {
+				attempt--
+				continue
+			}
+		}
+
+		if ctx.Err() != nil || attempt >= policy.MaxAttempts ||
+			!policy.ShouldRetry(reqConfig.IsIdempotent(), statusCode, err) {
+			return bodyBytes, rateLimit, err
+		}
+
+		delay := policy.Delay(attempt)
+		t.logger().Warning("Request failed, retrying",
+			utils.KeyOperation, reqConfig.Operation, utils.KeyAttempt, attempt, "max_attempts", policy.MaxAttempts, "delay", delay, utils.KeyError, err)
+		if err := utils.SleepDuration(ctx, delay); err != nil {
+			return bodyBytes, rateLimit, err
+		}
+	}
+}
+
+// send performs a single attempt. statusCode is 0 if no response was received.
+func (t *Twitter) send(ctx context.Context, reqConfig utils.RequestConfig) ([]byte, *models.RateLimit, int, error) {
+	// The cookie header is always built here from the jar, for the request
+	// URL. Credentials may have rotated since the request was built, by an
+	// earlier attempt or a concurrent request, so the csrf token is also
+	// replaced with the current one.
+	cookieHeader, ct0 := t.credentials(reqConfig.URL)
+	reqConfig.DelHeader("cookie")
+	if cookieHeader != "" {
+		reqConfig.SetHeader("cookie", cookieHeader)
+	}
+	if t.guest {
+		// Guest requests authenticate with x-guest-token alone
+		reqConfig.DelHeader("x-csrf-token")
+		reqConfig.DelHeader("x-twitter-auth-type")
+	} else if reqConfig.GetHeader("x-csrf-token") != "" {
+		reqConfig.SetHeader("x-csrf-token", ct0)
+	}
+
+	start := time.Now()
+	bodyBytes, resp, err := utils.Send(ctx, t.roundTripper(), reqConfig)
+	if err != nil {
+		err = t.redactor.RedactError(err)
+		t.logger().Debug("Request failed",
+			utils.KeyOperation, reqConfig.Operation, utils.KeyLatency, time.Since(start), utils.KeyError, err)
+		return nil, nil, 0, err
+	}
+	t.logger().Debug("Request finished",
+		utils.KeyOperation, reqConfig.Operation, utils.KeyStatus, resp.StatusCode, utils.KeyLatency, time.Since(start))
+
+	// Update cookies
+	t.updateCookies(reqConfig.URL, resp)
+
+	rateLimit := models.ParseRateLimit(reqConfig.Operation, resp.Header)
+	t.recordRateLimit(rateLimit)
+
+	if apiErr := models.DecodeAPIError(resp.StatusCode, bodyBytes); apiErr != nil {
+		apiErr.Message = t.redactor.Redact(apiErr.Message)
+		t.observeRateLimit(reqConfig.Operation, rateLimit, apiErr)
+		return bodyBytes, rateLimit, resp.StatusCode, apiErr
+	}
+	t.observeRateLimit(reqConfig.Operation, rateLimit, nil)
+
+	return bodyBytes, rateLimit, resp.StatusCode, nil
+}
+
+// updateCookies stores the cookies set by a response to rawURL, refreshes
+// ct0 and saves the jar to Account.CookieStore if the response changed it
+func (t *Twitter) updateCookies(rawURL string, resp *http.Response) {
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		u = nil
+	}
+	t.Cookies.SetCookies(u, cookies)
+	for _, cookie := range cookies {
+		if cookie.Name == "auth_token" || cookie.Name == "ct0" {
+			t.redactor.AddSecret(cookie.Value)
+		}
+	}
+	if newCt0, ok := t.Cookies.Value(t.Config.WebURL("/"), "ct0"); ok {
+		t.setCsrfToken(newCt0)
+	}
+
+	t.saveCookies()
+}
+
+// saveCookies writes the jar to Account.CookieStore, if there is one
+func (t *Twitter) saveCookies() {
+	store := t.Account.CookieStore
+	if store == nil {
+		return
+	}
+	if err := t.Cookies.Save(store); err != nil {
+		t.logger().Warning("Failed to save cookies", utils.KeyError, t.redactor.RedactError(err))
+	}
+}
+
+// errorResponse builds a failed ActionResponse whose status matches err
+func errorResponse(err error, rateLimit *models.RateLimit) *models.ActionResponse {
+	return &models.ActionResponse{
+		Success:   false,
+		Error:     err,
+		Status:    models.StatusFromError(err),
+		RateLimit: rateLimit,
+	}
+}
@@ -0,0 +1,90 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+var errConnReset = errors.New("connection reset by peer")
+
+// dropConnections is a middleware failing the first n requests to
+// operation with a transport error, before they reach the server
+func dropConnections(operation string, n int) utils.Middleware {
+	var mu sync.Mutex
+	return func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			mu.Lock()
+			drop := req.Operation == operation && n > 0
+			if drop {
+				n--
+			}
+			mu.Unlock()
+			if drop {
+				return nil, errConnReset
+			}
+			return next.RoundTrip(ctx, req)
+		})
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	for _, tt := range []struct{ maxAttempts, calls int }{{0, 1}, {1, 1}, {3, 3}, {5, 5}} {
+		srv, twitter := newClient(t, func(c *models.Config) { c.Retry.MaxAttempts = tt.maxAttempts })
+		srv.Inject("UserByScreenName", twittertest.ServerError(503))
+
+		if _, resp := twitter.GetUserInfoByUsername("bob"); resp.Success {
+			t.Errorf("MaxAttempts %d: GetUserInfoByUsername() succeeded against a failing server", tt.maxAttempts)
+		}
+		if n := srv.Calls("UserByScreenName"); n != tt.calls {
+			t.Errorf("MaxAttempts %d: UserByScreenName calls = %d, want %d", tt.maxAttempts, n, tt.calls)
+		}
+	}
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	srv, twitter := newClient(t, nil)
+	twitter.Use(dropConnections("UserByScreenName", 2))
+
+	if _, resp := twitter.GetUserInfoByUsername("bob"); !resp.Success {
+		t.Errorf("GetUserInfoByUsername() = %+v, want success on the third attempt", resp)
+	}
+	if n := srv.Calls("UserByScreenName"); n != 1 {
+		t.Errorf("UserByScreenName calls = %d, want 1 that got through", n)
+	}
+}
+
+func TestRetryNotIdempotent(t *testing.T) {
+	srv, twitter := newClient(t, nil)
+	twitter.Use(dropConnections("CreateTweet", 1))
+
+	resp := twitter.Tweet("hello", nil)
+	if resp.Success || !errors.Is(resp.Error, errConnReset) {
+		t.Errorf("Tweet() = %+v, want the transport error", resp)
+	}
+	srv.Inject("CreateTweet", twittertest.ServerError(503))
+	if resp := twitter.Tweet("hello", nil); resp.Success {
+		t.Errorf("Tweet() = %+v, want the server error", resp)
+	}
+	if n := srv.Calls("CreateTweet"); n != 1 {
+		t.Errorf("CreateTweet calls = %d, want no retries", n)
+	}
+}
+
+func TestRetryNonIdempotentEnabled(t *testing.T) {
+	srv, twitter := newClient(t, func(c *models.Config) { c.Retry.RetryNonIdempotent = true })
+	twitter.Use(dropConnections("CreateTweet", 1))
+	srv.Inject("CreateTweet", twittertest.Fault{StatusCode: 503, Message: "Service Unavailable", Times: 1})
+
+	if resp := twitter.Tweet("hello", nil); !resp.Success {
+		t.Errorf("Tweet() = %+v, want success on the third attempt", resp)
+	}
+	if n := srv.Calls("CreateTweet"); n != 2 {
+		t.Errorf("CreateTweet calls = %d, want 2", n)
+	}
+}
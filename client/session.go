@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client/addons"
+	"github.com/Tootoohk/TwitterAPI/models"
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// SessionOptions controls ExportSession and RestoreSession
+type SessionOptions struct {
+	// Key encrypts the session with AES-GCM, see models.EncodeSession.
+	// Nil writes and expects plain JSON.
+	Key []byte
+
+	// Proxy, if set, replaces the proxy stored in the session on restore
+	Proxy string
+
+	// Validate runs the Viewer check on restore, like NewTwitter does.
+	// Without it no request is sent until the first API call.
+	Validate bool
+}
+
+// ExportSession serializes the cookie jar, ct0, username, user ID and
+// session timestamps, so the client can be recreated with RestoreSession
+// after a restart without running initialization again. Cookies updated by
+// X since NewTwitter are included.
+//
+// The output holds the account credentials; pass SessionOptions.Key to
+// encrypt it.
+//
+// Example:
+//
+//	data, err := twitter.ExportSession(&client.SessionOptions{Key: key})
+//	if err == nil {
+//	    err = os.WriteFile("alice.session", data, 0o600)
+//	}
+func (t *Twitter) ExportSession(opts *SessionOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &SessionOptions{}
+	}
+
+	t.mu.RLock()
+	session := &models.Session{
+		Username:    t.Account.Username,
+		UserID:      t.Account.UserID,
+		AuthToken:   t.Account.AuthToken,
+		Ct0:         t.Account.Ct0,
+		Proxy:       t.Account.Proxy,
+		CreatedAt:   t.createdAt,
+		ValidatedAt: t.validatedAt,
+		ExportedAt:  time.Now(),
+	}
+	t.mu.RUnlock()
+
+	for _, cookie := range t.Cookies.All() {
+		stored := models.SessionCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		}
+		if !cookie.Expires.IsZero() {
+			expires := cookie.Expires
+			stored.Expires = &expires
+		}
+		session.Cookies = append(session.Cookies, stored)
+	}
+
+	return models.EncodeSession(session, opts.Key)
+}
+
+// RestoreSession creates a client from a session written by ExportSession
+func RestoreSession(data []byte, config *models.Config, opts *SessionOptions) (*Twitter, error) {
+	return RestoreSessionContext(context.Background(), data, config, opts)
+}
+
+// RestoreSessionContext is like RestoreSession but runs the optional
+// Viewer check under ctx.
+func RestoreSessionContext(ctx context.Context, data []byte, config *models.Config, opts *SessionOptions) (*Twitter, error) {
+	if opts == nil {
+		opts = &SessionOptions{}
+	}
+
+	session, err := models.DecodeSession(data, opts.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore session: %w", err)
+	}
+
+	account := &models.Account{
+		AuthToken: session.AuthToken,
+		Ct0:       session.Ct0,
+		Proxy:     session.Proxy,
+		Username:  session.Username,
+		UserID:    session.UserID,
+	}
+	if opts.Proxy != "" {
+		account.Proxy = opts.Proxy
+	}
+
+	t := newTwitter(account, config)
+	t.createdAt = session.CreatedAt
+	t.validatedAt = session.ValidatedAt
+
+	cookies := make([]http.Cookie, 0, len(session.Cookies))
+	for _, cookie := range session.Cookies {
+		restored := http.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		}
+		if cookie.Expires != nil {
+			restored.Expires = *cookie.Expires
+		}
+		cookies = append(cookies, restored)
+		t.redactor.AddSecret(cookie.Value)
+	}
+	t.Cookies.AddCookies(cookies)
+
+	// Requests authenticate with the jar, so keep it in line with the
+	// credentials if the cookie list was trimmed
+	domain := t.Config.CookieDomain()
+	for _, cookie := range []http.Cookie{{Name: "auth_token", Value: session.AuthToken, Domain: domain}, {Name: "ct0", Value: session.Ct0, Domain: domain}} {
+		if _, ok := t.Cookies.GetCookieValue(cookie.Name); !ok && cookie.Value != "" {
+			t.Cookies.AddCookies([]http.Cookie{cookie})
+		}
+	}
+
+	client, err := t.newHttpClient()
+	if err != nil {
+		return nil, t.redactor.RedactError(fmt.Errorf("failed to restore session: %w", err))
+	}
+	t.Client = client
+	t.transport = t.newTransport()
+
+	if t.Config.QueryIDDiscovery.Enabled {
+		t.discoverQueryIDs(ctx)
+	}
+
+	if opts.Validate {
+		viewer, newCsrfToken, err, status := addons.GetViewerContext(ctx, t.roundTripper(), t.Cookies, t.Config, t.baseLogger(), t.csrfToken())
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if fatal := viewerError(status, err); fatal != nil {
+				err = fatal
+			}
+			return nil, t.redactor.RedactError(fmt.Errorf("failed to validate session: %w", err))
+		}
+		t.setViewer(viewer, newCsrfToken)
+	}
+
+	t.logger().Success("Restored session")
+	return t, nil
+}
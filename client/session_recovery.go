@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client/addons"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// sessionRejected reports whether err may go away once ct0 is refreshed
+func sessionRejected(err error) bool {
+	return errors.Is(err, models.ErrBadCSRF) || errors.Is(err, models.ErrAuthFailed)
+}
+
+// canRecoverSession reports whether a rejected request may be retried
+// after refreshSession. Guest requests and the login flow have no
+// session to refresh, and a session rejected while logging out or revoking
+// logins has already ended.
+func (t *Twitter) canRecoverSession(reqConfig utils.RequestConfig) bool {
+	switch {
+	case t.guest, !t.Config.SessionRecovery.Enabled:
+		return false
+	case reqConfig.Operation == models.PathGuestActivate, reqConfig.Operation == models.PathOnboardingTask:
+		return false
+	case reqConfig.Operation == models.PathAccountLogout, reqConfig.Operation == models.PathSessionsRevoke:
+		return false
+	}
+	return reqConfig.GetHeader("x-guest-token") == ""
+}
+
+// refreshSession re-runs the Viewer lookup after a request sent at sent
+// failed with cause, which stores the ct0 X rotated to. Requests rejected
+// together share one lookup: if another refresh finished after sent,
+// the request is simply retried.
+//
+// It returns nil if the request should be sent again.
+func (t *Twitter) refreshSession(ctx context.Context, operation string, cause error, sent time.Time) error {
+	t.sessionMu.Lock()
+	defer t.sessionMu.Unlock()
+	if t.sessionRefreshedAt.After(sent) {
+		return nil
+	}
+
+	t.logger().Warning("Session rejected, refreshing ct0", utils.KeyOperation, operation, utils.KeyError, cause)
+	viewer, newCsrfToken, err, status := addons.GetViewerContext(ctx, t.roundTripper(), t.Cookies, t.Config, t.baseLogger(), t.csrfToken())
+	if err == nil {
+		t.setViewer(viewer, newCsrfToken)
+		t.saveCookies()
+		t.sessionRefreshedAt = time.Now()
+		t.logger().Success("Refreshed session", utils.KeyOperation, operation)
+	} else {
+		if fatal := viewerError(status, err); fatal != nil {
+			err = fatal
+		}
+		err = t.redactor.RedactError(err)
+		t.logger().Error("Failed to refresh session", utils.KeyOperation, operation, utils.KeyError, err)
+	}
+
+	if onRecover := t.Config.SessionRecovery.OnRecover; onRecover != nil {
+		onRecover(models.SessionRecoveryEvent{Operation: operation, Cause: cause, Err: err})
+	}
+	return err
+}
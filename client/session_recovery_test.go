@@ -0,0 +1,83 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+)
+
+// recoveryEvents records the SessionRecovery events of config
+func recoveryEvents(config *models.Config) func() []models.SessionRecoveryEvent {
+	var mu sync.Mutex
+	var events []models.SessionRecoveryEvent
+	config.SessionRecovery.OnRecover = func(e models.SessionRecoveryEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}
+	return func() []models.SessionRecoveryEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]models.SessionRecoveryEvent(nil), events...)
+	}
+}
+
+func TestSessionRecoverySkipsLogin(t *testing.T) {
+	srv, opts := newLoginServer(t, twittertest.User{})
+	events := recoveryEvents(opts.Config)
+	srv.Inject(twittertest.OpOnboardingTask, twittertest.Fault{StatusCode: 403, Code: 353, Message: "csrf", Times: 1})
+
+	_, err := client.Login(context.Background(), "carol", testPassword, opts)
+	if !errors.Is(err, models.ErrBadCSRF) {
+		t.Errorf("Login() error = %v, want ErrBadCSRF", err)
+	}
+	if n := srv.Calls("Viewer"); n != 0 {
+		t.Errorf("Viewer calls = %d, want no session refresh", n)
+	}
+	if got := events(); len(got) != 0 {
+		t.Errorf("recovery events = %+v, want none", got)
+	}
+}
+
+func TestSessionRecoverySkipsGuest(t *testing.T) {
+	srv := twittertest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddUser(twittertest.User{ScreenName: "alice"})
+
+	config := srv.Config()
+	events := recoveryEvents(config)
+	guest, err := client.NewGuestTwitter("", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Inject("UserByScreenName", twittertest.Fault{StatusCode: 403, Code: 353, Message: "csrf", Times: 1})
+
+	if _, resp := guest.GetUserInfoByUsername("alice"); resp.Success || !errors.Is(resp.Error, models.ErrBadCSRF) {
+		t.Errorf("GetUserInfoByUsername() = %+v, want ErrBadCSRF", resp)
+	}
+	if n := srv.Calls("Viewer"); n != 0 {
+		t.Errorf("Viewer calls = %d, want no session refresh", n)
+	}
+	if got := events(); len(got) != 0 {
+		t.Errorf("recovery events = %+v, want none", got)
+	}
+}
+
+func TestSessionRecoveryRetriesRejectedRequest(t *testing.T) {
+	srv, twitter := newClient(t, nil)
+	tweet := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "hello"})
+	srv.Inject("FavoriteTweet", twittertest.Fault{StatusCode: 403, Code: 353, Message: "csrf", Times: 1})
+	viewerCalls := srv.Calls("Viewer")
+
+	if resp := twitter.Like(tweet.ID); !resp.Success {
+		t.Fatalf("Like() error = %v, want success after the refresh", resp.Error)
+	}
+	if n := srv.Calls("Viewer") - viewerCalls; n != 1 {
+		t.Errorf("Viewer calls = %d, want one refresh", n)
+	}
+}
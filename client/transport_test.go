@@ -0,0 +1,45 @@
+package client_test
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+func TestInsecureSkipVerify(t *testing.T) {
+	srv := twittertest.NewServer()
+	t.Cleanup(srv.Close)
+	tlsSrv := httptest.NewUnstartedServer(srv.Server.Config.Handler)
+	tlsSrv.Config.ErrorLog = log.New(io.Discard, "", 0) // Rejected handshakes
+	tlsSrv.StartTLS()
+	t.Cleanup(tlsSrv.Close)
+
+	// A built client instead of the server's, pointed at a self-signed host
+	overTLS := func(insecure bool) func(*models.Config) {
+		return func(c *models.Config) {
+			c.Hosts = models.Hosts{Web: tlsSrv.URL, API: tlsSrv.URL, Upload: tlsSrv.URL, Caps: tlsSrv.URL}
+			c.HttpClient = nil
+			c.Transport = utils.TransportStd
+			c.InsecureSkipVerify = insecure
+			c.Retry.MaxAttempts = 1
+		}
+	}
+
+	twitter := srv.NewClient(t, "alice", overTLS(true))
+	if _, resp := twitter.IsValid(); !resp.Success {
+		t.Errorf("IsValid() = %+v, want the self-signed certificate accepted", resp)
+	}
+
+	alice, _ := srv.GetUser("alice")
+	config := srv.Config()
+	overTLS(false)(config)
+	if _, err := client.NewTwitter(client.NewAccount(alice.AuthToken, "", ""), config); err == nil {
+		t.Error("NewTwitter() accepted a self-signed certificate without InsecureSkipVerify")
+	}
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -32,18 +33,32 @@ import (
 //	    fmt.Println("Successfully unfollowed user")
 //	}
 func (t *Twitter) Unfollow(userIDOrUsername string) *models.ActionResponse {
+	return t.UnfollowContext(context.Background(), userIDOrUsername)
+}
+
+// UnfollowContext is like Unfollow but uses ctx for both the optional
+// username lookup and the unfollow request.
+func (t *Twitter) UnfollowContext(ctx context.Context, userIDOrUsername string) *models.ActionResponse {
 	// Check if the input is not a numeric ID
+	userIDOrUsername = strings.TrimSpace(userIDOrUsername)
 	if !utils.IsNumeric(userIDOrUsername) {
 		// Get user info to get the numeric ID
-		info, resp := t.GetUserInfoByUsername(userIDOrUsername)
+		info, resp := t.GetUserInfoByUsernameContext(ctx, userIDOrUsername)
 		if !resp.Success {
 			return resp
 		}
 		userIDOrUsername = info.Data.User.Result.RestID
 	}
+	if err := models.ValidateUserID(userIDOrUsername); err != nil {
+		return &models.ActionResponse{
+			Success: false,
+			Error:   err,
+			Status:  models.StatusUnknown,
+		}
+	}
 
 	// Build URL and request body
-	baseURL := "https://x.com/i/api/1.1/friendships/destroy.json"
+	baseURL := t.Config.URL(t.Config.Hosts.Web, models.PathFriendshipsDestroy)
 	data := url.Values{}
 	data.Set("include_profile_interstitial_type", "1")
 	data.Set("include_blocking", "1")
@@ -60,84 +75,53 @@ func (t *Twitter) Unfollow(userIDOrUsername string) *models.ActionResponse {
 	data.Set("user_id", userIDOrUsername)
 
 	// Create request config
-	reqConfig := utils.DefaultConfig()
+	reqConfig := t.Config.NewRequest()
 	reqConfig.Method = "POST"
 	reqConfig.URL = baseURL
+	reqConfig.Idempotent = true // Unfollowing twice is a no-op
 	reqConfig.Body = strings.NewReader(data.Encode())
 	reqConfig.Headers = append(reqConfig.Headers,
 		utils.HeaderPair{Key: "accept", Value: "*/*"},
 		utils.HeaderPair{Key: "authorization", Value: t.Config.Constants.BearerToken},
 		utils.HeaderPair{Key: "content-type", Value: "application/x-www-form-urlencoded"},
-		utils.HeaderPair{Key: "cookie", Value: t.Cookies.CookiesToHeader()},
-		utils.HeaderPair{Key: "origin", Value: "https://x.com"},
-		utils.HeaderPair{Key: "x-csrf-token", Value: t.Account.Ct0},
+		utils.HeaderPair{Key: "x-csrf-token", Value: t.csrfToken()},
 		utils.HeaderPair{Key: "x-twitter-active-user", Value: "yes"},
 		utils.HeaderPair{Key: "x-twitter-auth-type", Value: "OAuth2Session"},
 	)
 
 	// Make the request
-	bodyBytes, resp, err := utils.MakeRequest(t.Client, reqConfig)
+	bodyBytes, rateLimit, err := t.doRequest(ctx, reqConfig)
 	if err != nil {
-		t.Logger.Error("%s | Failed to unfollow user %s: %v", t.Account.Username, userIDOrUsername, err)
-		return &models.ActionResponse{
-			Success: false,
-			Error:   err,
-			Status:  models.StatusUnknown,
-		}
-	}
-
-	// Update cookies
-	t.Cookies.SetCookieFromResponse(resp)
-	if newCt0, ok := t.Cookies.GetCookieValue("ct0"); ok {
-		t.Account.Ct0 = newCt0
+		t.logger().Error("Failed to unfollow user", utils.KeyUser, userIDOrUsername, utils.KeyError, err)
+		return errorResponse(err, rateLimit)
 	}
 
-	bodyString := string(bodyBytes)
-
-	// Handle successful responses
-	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
-		var response models.UserResponse
-		if err := json.Unmarshal(bodyBytes, &response); err != nil {
-			t.Logger.Error("%s | Failed to parse unfollow response: %v", t.Account.Username, err)
-			return &models.ActionResponse{
-				Success: false,
-				Error:   err,
-				Status:  models.StatusUnknown,
-			}
-		}
-
-		// Check if we got a valid user response (contains screen_name)
-		if response.ScreenName != "" {
-			t.Logger.Success("%s | Successfully unfollowed user %s", t.Account.Username, userIDOrUsername)
-			return &models.ActionResponse{
-				Success: true,
-				Status:  models.StatusSuccess,
-			}
+	var response models.UserResponse
+	if err := json.Unmarshal(bodyBytes, &response); err != nil {
+		t.logger().Error("Failed to parse unfollow response", utils.KeyError, err)
+		return &models.ActionResponse{
+			Success:   false,
+			Error:     err,
+			Status:    models.StatusUnknown,
+			RateLimit: rateLimit,
 		}
 	}
 
-	// Handle error responses
-	switch {
-	case strings.Contains(bodyString, "this account is temporarily locked"):
-		t.Logger.Error("%s | Account is temporarily locked", t.Account.Username)
-		return &models.ActionResponse{
-			Success: false,
-			Error:   models.ErrAccountLocked,
-			Status:  models.StatusLocked,
-		}
-	case strings.Contains(bodyString, "Could not authenticate you"):
-		t.Logger.Error("%s | Could not authenticate you", t.Account.Username)
+	// Check if we got a valid user response (contains screen_name)
+	if response.ScreenName == "" {
+		t.logger().Error("Failed to unfollow user: unexpected response", utils.KeyUser, userIDOrUsername)
 		return &models.ActionResponse{
-			Success: false,
-			Error:   models.ErrAuthFailed,
-			Status:  models.StatusAuthError,
-		}
-	default:
-		t.Logger.Error("%s | Unknown response: %s", t.Account.Username, bodyString)
-		return &models.ActionResponse{
-			Success: false,
-			Error:   fmt.Errorf("unknown response: %s", bodyString),
-			Status:  models.StatusUnknown,
+			Success:   false,
+			Error:     fmt.Errorf("unexpected response: %s", t.redactor.Redact(string(bodyBytes))),
+			Status:    models.StatusUnknown,
+			RateLimit: rateLimit,
 		}
 	}
+
+	t.logger().Success("Successfully unfollowed user", utils.KeyUser, userIDOrUsername)
+	return &models.ActionResponse{
+		Success:   true,
+		Status:    models.StatusSuccess,
+		RateLimit: rateLimit,
+	}
 }
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/url"
 	"strings"
 
@@ -27,44 +29,43 @@ import (
 //	}
 //	// Use mediaID in Tweet or Comment function
 func (t *Twitter) UploadMedia(mediaBase64 string) (string, error) {
-	mediaURL := "https://upload.twitter.com/1.1/media/upload.json"
+	return t.UploadMediaContext(context.Background(), mediaBase64)
+}
+
+// UploadMediaContext is like UploadMedia but aborts the upload when ctx
+// is cancelled.
+func (t *Twitter) UploadMediaContext(ctx context.Context, mediaBase64 string) (string, error) {
+	mediaURL := t.Config.URL(t.Config.Hosts.Upload, models.PathMediaUpload)
 	data := url.Values{}
 	data.Set("media_data", mediaBase64)
 
-	reqConfig := utils.DefaultConfig()
+	reqConfig := t.Config.NewRequest()
 	reqConfig.Method = "POST"
 	reqConfig.URL = mediaURL
 	reqConfig.Body = strings.NewReader(data.Encode())
 	reqConfig.Headers = append(reqConfig.Headers,
 		utils.HeaderPair{Key: "authorization", Value: t.Config.Constants.BearerToken},
 		utils.HeaderPair{Key: "content-type", Value: "application/x-www-form-urlencoded"},
-		utils.HeaderPair{Key: "cookie", Value: t.Cookies.CookiesToHeader()},
-		utils.HeaderPair{Key: "x-csrf-token", Value: t.Account.Ct0},
+		utils.HeaderPair{Key: "x-csrf-token", Value: t.csrfToken()},
 	)
 
-	bodyBytes, resp, err := utils.MakeRequest(t.Client, reqConfig)
+	bodyBytes, _, err := t.doRequest(ctx, reqConfig)
 	if err != nil {
-		t.Logger.Error("%s | Failed to upload media: %v", t.Account.Username, err)
+		t.logger().Error("Failed to upload media", utils.KeyError, err)
 		return "", err
 	}
 
-	// Update cookies
-	t.Cookies.SetCookieFromResponse(resp)
-	if newCt0, ok := t.Cookies.GetCookieValue("ct0"); ok {
-		t.Account.Ct0 = newCt0
-	}
-
 	var response models.MediaUploadResponse
 	if err := json.Unmarshal(bodyBytes, &response); err != nil {
-		t.Logger.Error("%s | Failed to parse media upload response: %v", t.Account.Username, err)
+		t.logger().Error("Failed to parse media upload response", utils.KeyError, err)
 		return "", err
 	}
 
 	if response.MediaIDString == "" {
-		t.Logger.Error("%s | No media ID in response", t.Account.Username)
-		return "", err
+		t.logger().Error("No media ID in response")
+		return "", fmt.Errorf("no media ID in response: %s", t.redactor.Redact(string(bodyBytes)))
 	}
 
-	t.Logger.Success("%s | Successfully uploaded media", t.Account.Username)
+	t.logger().Success("Successfully uploaded media")
 	return response.MediaIDString, nil
-} 
+}
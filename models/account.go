@@ -1,13 +1,14 @@
 package models
 
 import (
+	"github.com/Tootoohk/TwitterAPI/utils"
 	http "github.com/bogdanfinn/fhttp"
 )
 
 // Account represents a Twitter account with all necessary credentials and information
 type Account struct {
 	Ct0       string // CSRF token
-	AuthToken string // auth_token cookie
+	AuthToken string // auth_token cookie, or a cookie export holding it
 	Proxy     string // Format: "ip:port" or "user:pass@ip:port"
 
 	// Account Info
@@ -24,6 +25,11 @@ type Account struct {
 	// Session Data
 	Cookies []*http.Cookie
 
+	// CookieStore, if set, fills the cookie jar when the client starts and
+	// is saved whenever a response sets cookies. With a saved auth_token
+	// cookie, AuthToken may be left empty.
+	CookieStore utils.CookieStore
+
 	// Optional fields
 	ProfileImageURL string
 	Bio             string
@@ -1,6 +1,10 @@
 package models
 
 import (
+	"io"
+	"net"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Tootoohk/TwitterAPI/utils"
@@ -13,8 +17,11 @@ type TwitterConstants struct {
 	UserAgent   string
 
 	// Query IDs
+	//
+	// Deprecated: use Config.Operations or Config.QueryIDs. A value changed
+	// from the built-in ID still overrides the query ID in Operations.
 	QueryID struct {
-		Like    string
+		Like      string
 		Unlike    string
 		Retweet   string
 		Unretweet string
@@ -22,6 +29,121 @@ type TwitterConstants struct {
 	}
 }
 
+// queryID returns the deprecated QueryID field of an operation, or "" if
+// it is unset or still the built-in ID
+func (c TwitterConstants) queryID(operation string) string {
+	var id, builtin string
+	switch operation {
+	case "FavoriteTweet":
+		id, builtin = c.QueryID.Like, QueryIDLike
+	case "UnfavoriteTweet":
+		id, builtin = c.QueryID.Unlike, QueryIDUnlike
+	case "CreateRetweet":
+		id, builtin = c.QueryID.Retweet, QueryIDRetweet
+	case "DeleteRetweet":
+		id, builtin = c.QueryID.Unretweet, QueryIDUnretweet
+	case "CreateTweet":
+		id, builtin = c.QueryID.Tweet, QueryIDTweet
+	}
+	if id == builtin {
+		return ""
+	}
+	return id
+}
+
+// Hosts holds the base URLs (scheme and host, no trailing slash) that every
+// request is resolved against. Point them at a local server to run the
+// client against a stand-in, or at a gateway that forwards to X.
+type Hosts struct {
+	Web    string // x.com web API: /i/api/graphql and /i/api/1.1, also used for origin/referer
+	API    string // api.x.com: Viewer and account/multi/list
+	Upload string // media uploads
+	Caps   string // cards API used for poll votes
+}
+
+// WithDefaults returns h with every empty host replaced by the X default,
+// so a Config built as a struct literal still sends absolute URLs
+func (h Hosts) WithDefaults() Hosts {
+	if h.Web == "" {
+		h.Web = HostWeb
+	}
+	if h.API == "" {
+		h.API = HostAPI
+	}
+	if h.Upload == "" {
+		h.Upload = HostUpload
+	}
+	if h.Caps == "" {
+		h.Caps = HostCaps
+	}
+	return h
+}
+
+// Contains reports whether u has the scheme and host of one of the hosts,
+// after WithDefaults
+func (h Hosts) Contains(u *url.URL) bool {
+	h = h.WithDefaults()
+	for _, host := range []string{h.Web, h.API, h.Upload, h.Caps} {
+		base, err := url.Parse(host)
+		if err == nil && strings.EqualFold(base.Scheme, u.Scheme) && strings.EqualFold(base.Host, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryIDDiscoveryConfig controls fetching current GraphQL query IDs and
+// feature switches from the X web client when a client is created and
+// merging them into Operations, see addons.MergeOperations
+type QueryIDDiscoveryConfig struct {
+	Enabled bool
+
+	// CachePath is a JSON file shared by all clients, empty to disable caching
+	CachePath string
+
+	// TTL is how long cached query IDs are used before fetching them again
+	TTL time.Duration
+}
+
+// RateLimiterConfig controls client-side throttling. Requests wait for
+// the per-endpoint budget instead of being sent and rejected with 429.
+type RateLimiterConfig struct {
+	Enabled bool
+
+	// Default is the budget for endpoints without an entry in Endpoints.
+	// The zero rule only enforces the windows X reports in x-rate-limit-* headers.
+	Default   utils.RateLimitRule
+	Endpoints map[string]utils.RateLimitRule // Keyed like Twitter.RateLimit
+
+	// MaxWait makes requests fail with ErrRateLimited instead of waiting
+	// longer than this. Zero waits as long as needed.
+	MaxWait time.Duration
+
+	// Backoff is how long an endpoint is paused after a 429 that carries no reset time
+	Backoff time.Duration
+
+	// OnWait, if set, is called before every wait
+	OnWait func(endpoint string, wait time.Duration)
+}
+
+// SessionRecoveryConfig controls how requests recover when X rejects the
+// session with a CSRF mismatch (code 353) or "Could not authenticate you"
+// (code 32). The client re-runs the Viewer lookup, which picks up the
+// rotated ct0, and sends the failed request once more.
+type SessionRecoveryConfig struct {
+	Enabled bool
+
+	// OnRecover, if set, is called after every refresh attempt
+	OnRecover func(event SessionRecoveryEvent)
+}
+
+// SessionRecoveryEvent describes a session refresh triggered by a request
+type SessionRecoveryEvent struct {
+	Operation string // Operation of the request that was rejected
+	Cause     error  // Error the request failed with
+	Err       error  // Why the refresh failed, nil if the request is retried
+}
+
 // Config holds Twitter client configuration
 type Config struct {
 	// HTTP Client settings
@@ -29,36 +151,161 @@ type Config struct {
 	Timeout         time.Duration
 	FollowRedirects bool
 
+	// Transport selects the HTTP implementation built for each account.
+	// Ignored when HttpClient is set.
+	Transport utils.TransportType
+
+	// InsecureSkipVerify disables certificate checks for utils.TransportStd,
+	// see utils.HttpClientOptions. Ignored when HttpClient is set.
+	InsecureSkipVerify bool
+
+	// HttpClient, if set, is used for every request instead of building
+	// a client from Transport (e.g. utils.NewStdHttpClient(server.Client())).
+	HttpClient utils.HttpClient
+
 	// Logging options
-	LogLevel utils.LogLevel // Level of logging detail
+	LogLevel  utils.LogLevel  // Level of logging detail
+	LogFormat utils.LogFormat // Console (default), text or JSON
+	LogOutput io.Writer       // Where the default logger writes, stdout if nil
+
+	// Logger, if set, receives all logs instead of the default logger built
+	// from the options above (e.g. utils.NewSlogLogger(slog.Default())).
+	Logger utils.Logger
+
+	// Unredacted disables scrubbing of auth tokens, cookies and bearer
+	// tokens from logs and errors. Only enable it for local debugging.
+	Unredacted bool
+
+	// API hosts used to build request URLs
+	Hosts Hosts
+
+	// Client-side rate limiting
+	RateLimiter RateLimiterConfig
+
+	// Retry policy applied to every request
+	Retry RetryPolicy
+
+	// Refreshing the session after CSRF and authentication errors
+	SessionRecovery SessionRecoveryConfig
+
+	// Middlewares wrap every request, including the ones made during
+	// initialization. The first middleware is the outermost.
+	Middlewares []utils.Middleware
 
 	// Twitter Constants
 	Constants TwitterConstants
+
+	// Operations describes every GraphQL operation the client calls,
+	// see LoadOperations. Nil uses the operations built into this release.
+	Operations *OperationRegistry
+
+	// QueryIDs overrides the query IDs in Operations by operation name,
+	// e.g. {"FavoriteTweet": "..."}
+	QueryIDs map[string]string
+
+	// Query ID auto-discovery, disabled by default
+	QueryIDDiscovery QueryIDDiscoveryConfig
 }
 
 // NewConfig returns a Config with default settings
 func NewConfig() *Config {
-	return &Config{
+	config := &Config{
 		MaxRetries:      3,
 		Timeout:         30 * time.Second,
 		FollowRedirects: true,
+		Transport:       utils.TransportTLS,
 		LogLevel:        utils.LogLevelError, // By default, only log errors
+		Hosts: Hosts{
+			Web:    HostWeb,
+			API:    HostAPI,
+			Upload: HostUpload,
+			Caps:   HostCaps,
+		},
+		RateLimiter: RateLimiterConfig{
+			Enabled: true,
+			MaxWait: 15 * time.Minute,
+			Backoff: time.Minute,
+		},
+		Retry: DefaultRetryPolicy(),
+		SessionRecovery: SessionRecoveryConfig{
+			Enabled: true,
+		},
+		QueryIDDiscovery: QueryIDDiscoveryConfig{
+			TTL: 24 * time.Hour,
+		},
+		Operations: DefaultOperations(),
 		Constants: TwitterConstants{
 			UserAgent:   UserAgent,
 			BearerToken: BearerToken,
-			QueryID: struct {
-				Like    string
-				Unlike  string
-				Retweet string
-				Unretweet string
-				Tweet   string
-			}{
-				Like:      QueryIDLike,
-				Unlike:    QueryIDUnlike,
-				Retweet:   QueryIDRetweet,
-				Unretweet: QueryIDUnretweet,
-				Tweet:     QueryIDTweet,
-			},
 		},
 	}
+
+	queryID := &config.Constants.QueryID
+	queryID.Like = QueryIDLike
+	queryID.Unlike = QueryIDUnlike
+	queryID.Retweet = QueryIDRetweet
+	queryID.Unretweet = QueryIDUnretweet
+	queryID.Tweet = QueryIDTweet
+	return config
+}
+
+// URL joins a host from Config.Hosts with an endpoint path
+func (c *Config) URL(host, path string) string {
+	return strings.TrimSuffix(host, "/") + path
+}
+
+// GraphQLURL returns the web GraphQL URL for an operation,
+// e.g. https://x.com/i/api/graphql/<queryID>/FavoriteTweet
+func (c *Config) GraphQLURL(queryID, operation string) string {
+	return c.URL(c.Hosts.WithDefaults().Web, PathWebGraphQL+"/"+queryID+"/"+operation)
+}
+
+// WebURL returns a page URL on the web host, used for origin and referer headers
+func (c *Config) WebURL(path string) string {
+	return c.URL(c.Hosts.WithDefaults().Web, path)
+}
+
+// Origin returns the web origin sent in origin headers
+func (c *Config) Origin() string {
+	return strings.TrimSuffix(c.Hosts.WithDefaults().Web, "/")
+}
+
+// CookieDomain returns the domain of cookies the client creates itself,
+// e.g. a generated ct0: ".x.com" for https://x.com, so they reach the same
+// hosts as the cookies X sets. Hosts without a dot and IP addresses, as
+// used by local test servers, get host-only cookies.
+func (c *Config) CookieDomain() string {
+	u, err := url.Parse(c.Hosts.WithDefaults().Web)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	if !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+		return host
+	}
+	return "." + host
+}
+
+// NewRequest returns utils.DefaultConfig with the origin header set to
+// the web host
+func (c *Config) NewRequest() utils.RequestConfig {
+	reqConfig := utils.DefaultConfig()
+	reqConfig.SetHeader("origin", c.Origin())
+	return reqConfig
+}
+
+// Redactor returns a Redactor that also scrubs secrets, or nil if
+// Unredacted is set
+func (c *Config) Redactor(secrets ...string) *utils.Redactor {
+	if c.Unredacted {
+		return nil
+	}
+	return utils.NewRedactor(secrets...)
+}
+
+// QueryIDFor returns the query ID to use for a GraphQL operation, see
+// Operation. It returns "" for unknown operations.
+func (c *Config) QueryIDFor(operation string) string {
+	op, _ := c.Operation(operation)
+	return op.QueryID
 }
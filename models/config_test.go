@@ -0,0 +1,28 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+)
+
+func TestCookieDomain(t *testing.T) {
+	tests := []struct {
+		web  string
+		want string
+	}{
+		{"", ".x.com"},
+		{"https://x.com", ".x.com"},
+		{"https://X.com/", ".x.com"},
+		{"https://twitter.com", ".twitter.com"},
+		{"http://127.0.0.1:8080", "127.0.0.1"},
+		{"http://localhost:8080", "localhost"},
+		{"http://[::1]:8080", "::1"},
+	}
+	for _, tt := range tests {
+		config := &models.Config{Hosts: models.Hosts{Web: tt.web}}
+		if got := config.CookieDomain(); got != tt.want {
+			t.Errorf("CookieDomain() with web host %q = %q, want %q", tt.web, got, tt.want)
+		}
+	}
+}
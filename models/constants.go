@@ -12,6 +12,30 @@ const (
 	BearerToken = "Bearer AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs%3D1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA"
 )
 
+// Default API hosts, see Config.Hosts
+const (
+	HostWeb    = "https://x.com"
+	HostAPI    = "https://api.x.com"
+	HostUpload = "https://upload.twitter.com"
+	HostCaps   = "https://caps.twitter.com"
+)
+
+// Endpoint paths, relative to the host they are served from
+const (
+	PathWebGraphQL         = "/i/api/graphql"                          // Hosts.Web
+	PathAPIGraphQL         = "/graphql"                                // Hosts.API
+	PathFriendshipsCreate  = "/i/api/1.1/friendships/create.json"      // Hosts.Web
+	PathFriendshipsDestroy = "/i/api/1.1/friendships/destroy.json"     // Hosts.Web
+	PathAccountMultiList   = "/1.1/account/multi/list.json"            // Hosts.API
+	PathMediaUpload        = "/1.1/media/upload.json"                  // Hosts.Upload
+	PathCapsPassthrough    = "/v2/capi/passthrough/1"                  // Hosts.Caps
+	PathGuestActivate      = "/1.1/guest/activate.json"                // Hosts.API
+	PathOnboardingTask     = "/1.1/onboarding/task.json"               // Hosts.API
+	PathAccountLogout      = "/1.1/account/logout.json"                // Hosts.API
+	PathSessionsList       = "/i/api/1.1/account/sessions/list.json"   // Hosts.Web
+	PathSessionsRevoke     = "/i/api/1.1/account/sessions/revoke.json" // Hosts.Web
+)
+
 // Query IDs for different operations
 const (
 	QueryIDLike      = "lI07N6Otwv1PhnEgXILM7A"
@@ -19,33 +43,57 @@ const (
 	QueryIDRetweet   = "ojPdsZsimiJrUGLR1sjUtA"
 	QueryIDUnretweet = "iQtK4dl5hBmXewYZLkNG9A"
 	QueryIDTweet     = "bDE2rBtZb3uyrczSZ_pI9g"
+
+	QueryIDTweetDetail      = "B9_KmbkLhXt6jRwGjJrweg"
+	QueryIDUserByScreenName = "32pL5BWe9WKeSK1MoPvFQQ"
+	QueryIDViewer           = "UhddhjWCl-JMqeiG4vPtvw"
 )
 
-// Common error types for Twitter operations
+// Common error types for Twitter operations.
+// Errors decoded from X responses (see APIError) wrap one of these,
+// so they can be checked with errors.Is.
 var (
 	ErrAccountLocked = errors.New("account is temporarily locked")
 	ErrAuthFailed    = errors.New("authentication failed")
 	ErrInvalidToken  = errors.New("invalid token")
 	ErrUnknown       = errors.New("unable to complete operation")
+	ErrRateLimited   = errors.New("rate limit exceeded")
+	ErrDailyLimit    = errors.New("daily limit reached")
+	ErrNotFound      = errors.New("not found")
+	ErrSuspended     = errors.New("account is suspended")
+	ErrProtected     = errors.New("account is protected")
+	ErrDuplicate     = errors.New("duplicate content")
+	ErrAlreadyDone   = errors.New("action was already done")
+	ErrBadCSRF       = errors.New("csrf token mismatch")
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrSessionKey    = errors.New("session key missing or wrong")
+
+	// Login and guest mode errors, see client.Login and client.NewGuestTwitter
+	ErrLoginFailed     = errors.New("login failed")
+	ErrLoginChallenge  = errors.New("login needs an answer that was not provided")
+	ErrGuestNotAllowed = errors.New("operation needs a logged-in account")
 )
 
 // ActionStatus represents the status of any Twitter action (like, retweet, etc.)
 type ActionStatus int
 
 const (
-	StatusSuccess     ActionStatus = iota
-	StatusAlreadyDone              // Already liked, already retweeted, etc.
-	StatusLocked                   // Account is locked
-	StatusNotFound                 // Tweet/User not found
-	StatusRateLimited              // Rate limit exceeded
-	StatusAuthError                // Authentication error
-	StatusInvalidToken             // Invalid token
-	StatusUnknown                  // Unknown error
+	StatusSuccess      ActionStatus = iota
+	StatusAlreadyDone               // Already liked, already retweeted, etc.
+	StatusLocked                    // Account is locked
+	StatusNotFound                  // Tweet/User not found
+	StatusRateLimited               // Rate limit exceeded
+	StatusAuthError                 // Authentication error
+	StatusInvalidToken              // Invalid token
+	StatusUnknown                   // Unknown error
+	StatusSuspended                 // Account is suspended
+	StatusProtected                 // Target is protected or not visible to the account
 )
 
 // ActionResponse represents the response from any Twitter action
 type ActionResponse struct {
-	Success bool
-	Error   error
-	Status  ActionStatus
+	Success   bool
+	Error     error
+	Status    ActionStatus
+	RateLimit *RateLimit // Rate limit reported with the response, nil if none
 }
@@ -0,0 +1,119 @@
+package models
+
+// builtinOperations backs Config.Operation when Config.Operations is nil
+var builtinOperations = DefaultOperations()
+
+// Feature flags shared by the user timeline and profile queries
+var userFeatures = map[string]bool{
+	"rweb_tipjar_consumption_enabled":                                   true,
+	"responsive_web_graphql_exclude_directive_enabled":                  true,
+	"verified_phone_label_enabled":                                      false,
+	"creator_subscriptions_tweet_preview_api_enabled":                   true,
+	"responsive_web_graphql_skip_user_profile_image_extensions_enabled": false,
+	"responsive_web_graphql_timeline_navigation_enabled":                true,
+}
+
+// defaultOperations are the GraphQL operations built into this release.
+// Update them with OperationRegistry.LoadFile when X changes a query ID
+// or starts requiring a new feature flag.
+var defaultOperations = []Operation{
+	{Name: "FavoriteTweet", QueryID: QueryIDLike, Method: "POST", Host: OperationHostWeb},
+	{Name: "UnfavoriteTweet", QueryID: QueryIDUnlike, Method: "POST", Host: OperationHostWeb},
+	{Name: "CreateRetweet", QueryID: QueryIDRetweet, Method: "POST", Host: OperationHostWeb},
+	{Name: "DeleteRetweet", QueryID: QueryIDUnretweet, Method: "POST", Host: OperationHostWeb},
+	{
+		Name:    "CreateTweet",
+		QueryID: QueryIDTweet,
+		Method:  "POST",
+		Host:    OperationHostWeb,
+		Features: map[string]bool{
+			"tweetypie_unmention_optimization_enabled":                                true,
+			"responsive_web_edit_tweet_api_enabled":                                   true,
+			"graphql_is_translatable_rweb_tweet_is_translatable_enabled":              true,
+			"view_counts_everywhere_api_enabled":                                      true,
+			"longform_notetweets_consumption_enabled":                                 true,
+			"responsive_web_twitter_article_tweet_consumption_enabled":                false,
+			"tweet_awards_web_tipping_enabled":                                        false,
+			"freedom_of_speech_not_reach_fetch_enabled":                               true,
+			"standardized_nudges_misinfo":                                             true,
+			"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+			"longform_notetweets_rich_text_read_enabled":                              true,
+			"longform_notetweets_inline_media_enabled":                                true,
+			"responsive_web_graphql_exclude_directive_enabled":                        true,
+			"verified_phone_label_enabled":                                            false,
+			"responsive_web_media_download_video_enabled":                             false,
+			"responsive_web_graphql_skip_user_profile_image_extensions_enabled":       false,
+			"responsive_web_graphql_timeline_navigation_enabled":                      true,
+			"rweb_video_timestamps_enabled":                                           false,
+			"c9s_tweet_anatomy_moderator_badge_enabled":                               false,
+			"responsive_web_enhance_cards_enabled":                                    false,
+		},
+	},
+	{
+		Name:    "TweetDetail",
+		QueryID: QueryIDTweetDetail,
+		Method:  "GET",
+		Host:    OperationHostWeb,
+		Features: map[string]bool{
+			"responsive_web_graphql_exclude_directive_enabled":                        true,
+			"verified_phone_label_enabled":                                            false,
+			"creator_subscriptions_tweet_preview_api_enabled":                         true,
+			"responsive_web_graphql_timeline_navigation_enabled":                      true,
+			"responsive_web_graphql_skip_user_profile_image_extensions_enabled":       false,
+			"tweetypie_unmention_optimization_enabled":                                true,
+			"responsive_web_edit_tweet_api_enabled":                                   true,
+			"graphql_is_translatable_rweb_tweet_is_translatable_enabled":              true,
+			"view_counts_everywhere_api_enabled":                                      true,
+			"longform_notetweets_consumption_enabled":                                 true,
+			"tweet_awards_web_tipping_enabled":                                        false,
+			"freedom_of_speech_not_reach_fetch_enabled":                               true,
+			"standardized_nudges_misinfo":                                             true,
+			"tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+			"longform_notetweets_rich_text_read_enabled":                              true,
+			"longform_notetweets_inline_media_enabled":                                true,
+			"responsive_web_enhance_cards_enabled":                                    false,
+		},
+		FieldToggles: map[string]bool{
+			"withArticleRichContentState": true,
+		},
+	},
+	{
+		Name:    "UserByScreenName",
+		QueryID: QueryIDUserByScreenName,
+		Method:  "GET",
+		Host:    OperationHostWeb,
+		Guest:   true,
+		Features: withFlags(userFeatures, map[string]bool{
+			"hidden_profile_subscriptions_enabled":                         true,
+			"subscriptions_feature_can_gift_premium":                       true,
+			"profile_label_improvements_pcf_label_in_post_enabled":         true,
+			"subscriptions_verification_info_is_identity_verified_enabled": true,
+			"subscriptions_verification_info_verified_since_enabled":       true,
+			"highlights_tweets_tab_ui_enabled":                             true,
+			"responsive_web_twitter_article_notes_tab_enabled":             true,
+		}),
+		FieldToggles: map[string]bool{
+			"withAuxiliaryUserLabels": false,
+		},
+	},
+	{
+		Name:     "Viewer",
+		QueryID:  QueryIDViewer,
+		Method:   "GET",
+		Host:     OperationHostAPI,
+		Features: userFeatures,
+		FieldToggles: map[string]bool{
+			"isDelegate":              false,
+			"withAuxiliaryUserLabels": false,
+		},
+	},
+}
+
+// withFlags returns base with extra added, leaving both maps unchanged
+func withFlags(base, extra map[string]bool) map[string]bool {
+	flags := cloneFlags(base)
+	for name, enabled := range extra {
+		flags[name] = enabled
+	}
+	return flags
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// GraphQLError is a single entry of the "errors" array returned by both
+// the GraphQL and the REST (1.1) APIs
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Code      int    `json:"code"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations"`
+	Path       []any `json:"path"`
+	Extensions struct {
+		Name    string `json:"name"`
+		Source  string `json:"source"`
+		Code    int    `json:"code"`
+		Kind    string `json:"kind"`
+		Tracing struct {
+			TraceID string `json:"trace_id"`
+		} `json:"tracing"`
+	} `json:"extensions"`
+}
+
+// ErrorCode returns the X error code of the entry, preferring extensions.code
+func (e GraphQLError) ErrorCode() int {
+	if e.Extensions.Code != 0 {
+		return e.Extensions.Code
+	}
+	return e.Code
+}
+
+// apiErrorCodes maps X error codes to the sentinel errors they represent
+var apiErrorCodes = map[int]error{
+	32:  ErrAuthFailed,    // Could not authenticate you
+	34:  ErrNotFound,      // Sorry, that page does not exist
+	50:  ErrNotFound,      // User not found
+	63:  ErrSuspended,     // User has been suspended
+	64:  ErrSuspended,     // Your account is suspended
+	88:  ErrRateLimited,   // Rate limit exceeded
+	89:  ErrInvalidToken,  // Invalid or expired token
+	139: ErrAlreadyDone,   // You have already favorited this status
+	144: ErrNotFound,      // No status found with that ID
+	160: ErrAlreadyDone,   // You've already requested to follow the user
+	179: ErrProtected,     // Not authorized to see this status
+	185: ErrDailyLimit,    // Over daily status update limit
+	187: ErrDuplicate,     // Status is a duplicate
+	215: ErrAuthFailed,    // Bad authentication data
+	239: ErrInvalidToken,  // Bad guest token
+	326: ErrAccountLocked, // This account is temporarily locked
+	327: ErrAlreadyDone,   // You have already retweeted this Tweet
+	344: ErrDailyLimit,    // Over the daily limit for this action
+	353: ErrBadCSRF,       // This request requires a matching csrf cookie and header
+	385: ErrNotFound,      // Replied-to tweet is deleted or not visible
+	399: ErrLoginFailed,   // Incorrect. Please try again (login flow)
+}
+
+// APIError is an error reported by X, decoded from a GraphQL or REST response.
+// It wraps the matching sentinel error (ErrRateLimited, ErrNotFound, ...)
+// so callers can use errors.Is, or errors.As to get at the code and message.
+type APIError struct {
+	StatusCode int    // HTTP status code of the response
+	Code       int    // X error code, 0 if the body carried none
+	Message    string // Error message as returned by X
+	Kind       string // GraphQL error kind (e.g. "Permissions"), if any
+	Err        error  // Matching sentinel error, nil if unknown
+}
+
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("x api error %d (http %d): %s", e.Code, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("x api error (http %d): %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeAPIError returns the error carried by an X response, or nil if the
+// response is a success. Error entries in the body take precedence; responses
+// without any are classified by their HTTP status code.
+func DecodeAPIError(statusCode int, body []byte) *APIError {
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []GraphQLError  `json:"errors"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	if len(envelope.Errors) > 0 {
+		// Prefer the first entry whose code we know
+		entry := envelope.Errors[0]
+		known := false
+		for _, e := range envelope.Errors {
+			if _, ok := apiErrorCodes[e.ErrorCode()]; ok {
+				entry, known = e, true
+				break
+			}
+		}
+
+		// GraphQL reports non-fatal field errors next to the data; only
+		// treat them as a failure when the data is missing
+		success := statusCode >= 200 && statusCode <= 299
+		if success && !known && hasData(envelope.Data) {
+			return nil
+		}
+
+		apiErr := &APIError{
+			StatusCode: statusCode,
+			Code:       entry.ErrorCode(),
+			Message:    entry.Message,
+			Kind:       entry.Extensions.Kind,
+			Err:        apiErrorCodes[entry.ErrorCode()],
+		}
+		if apiErr.Err == nil {
+			apiErr.Err = statusError(statusCode)
+		}
+		return apiErr
+	}
+
+	if statusCode >= 200 && statusCode <= 299 {
+		return nil
+	}
+
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    fmt.Sprintf("unknown response: %s", body),
+		Err:        statusError(statusCode),
+	}
+}
+
+// hasData reports whether a GraphQL data field carries anything
+func hasData(data json.RawMessage) bool {
+	trimmed := strings.TrimSpace(string(data))
+	return trimmed != "" && trimmed != "null" && trimmed != "{}"
+}
+
+// statusError maps HTTP status codes that carry a meaning on their own
+func statusError(statusCode int) error {
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		return ErrAuthFailed
+	default:
+		return nil
+	}
+}
+
+// StatusFromError maps an error to the ActionStatus reported in ActionResponse
+func StatusFromError(err error) ActionStatus {
+	switch {
+	case err == nil:
+		return StatusSuccess
+	case errors.Is(err, ErrAlreadyDone), errors.Is(err, ErrDuplicate):
+		return StatusAlreadyDone
+	case errors.Is(err, ErrAccountLocked):
+		return StatusLocked
+	case errors.Is(err, ErrNotFound):
+		return StatusNotFound
+	case errors.Is(err, ErrRateLimited), errors.Is(err, ErrDailyLimit):
+		return StatusRateLimited
+	case errors.Is(err, ErrInvalidToken):
+		return StatusInvalidToken
+	case errors.Is(err, ErrAuthFailed), errors.Is(err, ErrBadCSRF), errors.Is(err, ErrLoginFailed):
+		return StatusAuthError
+	case errors.Is(err, ErrSuspended):
+		return StatusSuspended
+	case errors.Is(err, ErrProtected):
+		return StatusProtected
+	default:
+		return StatusUnknown
+	}
+}
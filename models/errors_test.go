@@ -0,0 +1,132 @@
+package models_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+)
+
+func TestDecodeAPIErrorCodes(t *testing.T) {
+	tests := []struct {
+		code int
+		want error
+	}{
+		{32, models.ErrAuthFailed},
+		{34, models.ErrNotFound},
+		{50, models.ErrNotFound},
+		{63, models.ErrSuspended},
+		{64, models.ErrSuspended},
+		{88, models.ErrRateLimited},
+		{89, models.ErrInvalidToken},
+		{139, models.ErrAlreadyDone},
+		{144, models.ErrNotFound},
+		{160, models.ErrAlreadyDone},
+		{179, models.ErrProtected},
+		{185, models.ErrDailyLimit},
+		{187, models.ErrDuplicate},
+		{215, models.ErrAuthFailed},
+		{239, models.ErrInvalidToken},
+		{326, models.ErrAccountLocked},
+		{327, models.ErrAlreadyDone},
+		{344, models.ErrDailyLimit},
+		{353, models.ErrBadCSRF},
+		{385, models.ErrNotFound},
+		{399, models.ErrLoginFailed},
+	}
+	for _, tt := range tests {
+		for _, body := range []string{
+			fmt.Sprintf(`{"errors":[{"code":%d,"message":"error"}]}`, tt.code),
+			fmt.Sprintf(`{"errors":[{"message":"error","extensions":{"code":%d}}]}`, tt.code),
+		} {
+			err := models.DecodeAPIError(403, []byte(body))
+			if err == nil || err.Code != tt.code || !errors.Is(err, tt.want) {
+				t.Errorf("DecodeAPIError(%s) = %v, want code %d wrapping %v", body, err, tt.code, tt.want)
+			}
+		}
+	}
+}
+
+func TestDecodeAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       error // nil for a success, ErrUnknown for no sentinel
+		code       int
+	}{
+		{"success", 200, `{"data":{"favorite_tweet":"Done"}}`, nil, 0},
+		{"empty success", 200, ``, nil, 0},
+		{"partial data with unknown error", 200, `{"data":{"user":{}},"errors":[{"code":37,"message":"field failed"}]}`, nil, 0},
+		{"partial data with known error", 200, `{"data":{"user":{}},"errors":[{"code":37,"message":"field failed"},{"code":139,"message":"already"}]}`, models.ErrAlreadyDone, 139},
+		{"errors without data", 200, `{"data":null,"errors":[{"code":37,"message":"failed"}]}`, models.ErrUnknown, 37},
+		{"errors with empty data", 200, `{"data":{},"errors":[{"message":"failed"}]}`, models.ErrUnknown, 0},
+		{"unknown code uses status", 429, `{"errors":[{"code":37,"message":"slow down"}]}`, models.ErrRateLimited, 37},
+		{"no code 429", 429, `Rate limit exceeded`, models.ErrRateLimited, 0},
+		{"no code 404", 404, `{}`, models.ErrNotFound, 0},
+		{"no code 401", 401, ``, models.ErrAuthFailed, 0},
+		{"no code 500", 500, `<html>error</html>`, models.ErrUnknown, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.DecodeAPIError(tt.statusCode, []byte(tt.body))
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("DecodeAPIError() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("DecodeAPIError() = nil, want %v", tt.want)
+			}
+			if err.StatusCode != tt.statusCode || err.Code != tt.code {
+				t.Errorf("DecodeAPIError() = status %d code %d, want %d and %d", err.StatusCode, err.Code, tt.statusCode, tt.code)
+			}
+			if tt.want == models.ErrUnknown {
+				if err.Err != nil {
+					t.Errorf("DecodeAPIError() wraps %v, want no sentinel", err.Err)
+				}
+			} else if !errors.Is(err, tt.want) {
+				t.Errorf("DecodeAPIError() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusFromError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want models.ActionStatus
+	}{
+		{nil, models.StatusSuccess},
+		{models.ErrAlreadyDone, models.StatusAlreadyDone},
+		{models.ErrDuplicate, models.StatusAlreadyDone},
+		{models.ErrAccountLocked, models.StatusLocked},
+		{models.ErrNotFound, models.StatusNotFound},
+		{models.ErrRateLimited, models.StatusRateLimited},
+		{models.ErrDailyLimit, models.StatusRateLimited},
+		{models.ErrInvalidToken, models.StatusInvalidToken},
+		{models.ErrAuthFailed, models.StatusAuthError},
+		{models.ErrBadCSRF, models.StatusAuthError},
+		{models.ErrLoginFailed, models.StatusAuthError},
+		{models.ErrSuspended, models.StatusSuspended},
+		{models.ErrProtected, models.StatusProtected},
+		{models.ErrUnknown, models.StatusUnknown},
+		{models.ErrInvalidInput, models.StatusUnknown},
+		{models.ErrGuestNotAllowed, models.StatusUnknown},
+		{errors.New("connection reset"), models.StatusUnknown},
+	}
+	for _, tt := range tests {
+		if got := models.StatusFromError(tt.err); got != tt.want {
+			t.Errorf("StatusFromError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+		if tt.err == nil {
+			continue
+		}
+		wrapped := &models.APIError{StatusCode: 403, Message: "wrapped", Err: tt.err}
+		if got := models.StatusFromError(fmt.Errorf("request failed: %w", wrapped)); got != tt.want {
+			t.Errorf("StatusFromError(wrapped %v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -24,23 +24,7 @@ type LikeGraphQLResponse struct {
 	Data struct {
 		FavoriteTweet string `json:"favorite_tweet"`
 	} `json:"data"`
-	Errors []struct {
-		Message   string `json:"message"`
-		Locations []struct {
-			Line   int `json:"line"`
-			Column int `json:"column"`
-		} `json:"locations"`
-		Path       []string `json:"path"`
-		Extensions struct {
-			Name    string `json:"name"`
-			Source  string `json:"source"`
-			Code    int    `json:"code"`
-			Kind    string `json:"kind"`
-			Tracing struct {
-				TraceID string `json:"trace_id"`
-			} `json:"tracing"`
-		} `json:"extensions"`
-	} `json:"errors"`
+	Errors []GraphQLError `json:"errors"`
 }
 
 // UnlikeGraphQLResponse represents the GraphQL response for an unlike action
@@ -48,42 +32,10 @@ type UnlikeGraphQLResponse struct {
 	Data struct {
 		UnfavoriteTweet string `json:"unfavorite_tweet"`
 	} `json:"data"`
-	Errors []struct {
-		Message   string `json:"message"`
-		Locations []struct {
-			Line   int `json:"line"`
-			Column int `json:"column"`
-		} `json:"locations"`
-		Path       []string `json:"path"`
-		Extensions struct {
-			Name    string `json:"name"`
-			Source  string `json:"source"`
-			Code    int    `json:"code"`
-			Kind    string `json:"kind"`
-			Tracing struct {
-				TraceID string `json:"trace_id"`
-			} `json:"tracing"`
-		} `json:"extensions"`
-	} `json:"errors"`
+	Errors []GraphQLError `json:"errors"`
 }
 
 // AlreadyLikedResponse represents the response when tweet is already liked
 type AlreadyLikedResponse struct {
-	Errors []struct {
-		Message   string `json:"message"`
-		Locations []struct {
-			Line   int `json:"line"`
-			Column int `json:"column"`
-		} `json:"locations"`
-		Path       []string `json:"path"`
-		Extensions struct {
-			Name    string `json:"name"`
-			Source  string `json:"source"`
-			Code    int    `json:"code"`
-			Kind    string `json:"kind"`
-			Tracing struct {
-				TraceID string `json:"trace_id"`
-			} `json:"tracing"`
-		} `json:"extensions"`
-	} `json:"errors"`
+	Errors []GraphQLError `json:"errors"`
 }
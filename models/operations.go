@@ -0,0 +1,360 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// Hosts a GraphQL operation can be served from, see Operation.Host
+const (
+	OperationHostWeb = "web" // Hosts.Web + /i/api/graphql
+	OperationHostAPI = "api" // Hosts.API + /graphql
+)
+
+// Operation describes how to call one GraphQL operation
+type Operation struct {
+	Name         string          `json:"name,omitempty"`
+	QueryID      string          `json:"queryId,omitempty"`
+	Method       string          `json:"method,omitempty"` // GET sends everything as query parameters, POST as a JSON body
+	Host         string          `json:"host,omitempty"`   // OperationHostWeb (default) or OperationHostAPI
+	Features     map[string]bool `json:"features,omitempty"`
+	FieldToggles map[string]bool `json:"fieldToggles,omitempty"`
+	Guest        bool            `json:"guest,omitempty"` // Allowed without an account, see client.NewGuestTwitter
+}
+
+// clone returns a copy that shares no maps with o
+func (o Operation) clone() Operation {
+	o.Features = cloneFlags(o.Features)
+	o.FieldToggles = cloneFlags(o.FieldToggles)
+	return o
+}
+
+// merge applies the non-empty fields of override to o. Features and field
+// toggles given by override replace those of o as a whole, so a flag can be
+// removed; an empty map removes them all.
+func (o Operation) merge(override Operation) Operation {
+	o = o.clone()
+	if override.QueryID != "" {
+		o.QueryID = override.QueryID
+	}
+	if override.Method != "" {
+		o.Method = strings.ToUpper(override.Method)
+	}
+	if override.Host != "" {
+		o.Host = override.Host
+	}
+	if override.Guest {
+		o.Guest = true
+	}
+	if override.Features != nil {
+		o.Features = cloneFlags(override.Features)
+	}
+	if override.FieldToggles != nil {
+		o.FieldToggles = cloneFlags(override.FieldToggles)
+	}
+	return o
+}
+
+// WithFeatures returns a copy of o with features added to, or
+// overriding, its default feature flags
+func (o Operation) WithFeatures(features map[string]bool) Operation {
+	o = o.clone()
+	if len(features) > 0 && o.Features == nil {
+		o.Features = make(map[string]bool, len(features))
+	}
+	for name, enabled := range features {
+		o.Features[name] = enabled
+	}
+	return o
+}
+
+func cloneFlags(flags map[string]bool) map[string]bool {
+	if flags == nil {
+		return nil
+	}
+	clone := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		clone[name] = enabled
+	}
+	return clone
+}
+
+// OperationRegistry holds the GraphQL operations known to the client.
+// It is safe for concurrent use, so a registry shared by running clients
+// can be updated in place (e.g. with LoadFile) to hot-fix a query ID or
+// feature flag without restarting.
+//
+// The JSON form is an object keyed by operation name. A "features" or
+// "fieldToggles" object replaces the registered flags as a whole, and
+// "guest": false revokes guest access:
+//
+//	{
+//	    "FavoriteTweet": {"queryId": "lI07N6Otwv1PhnEgXILM7A"},
+//	    "UserByScreenName": {"guest": false},
+//	    "CreateTweet": {"features": {"rweb_video_timestamps_enabled": true}}
+//	}
+type OperationRegistry struct {
+	mu  sync.RWMutex
+	ops map[string]Operation
+}
+
+// NewOperationRegistry returns a registry holding ops
+func NewOperationRegistry(ops ...Operation) *OperationRegistry {
+	r := &OperationRegistry{ops: make(map[string]Operation, len(ops))}
+	r.Set(ops...)
+	return r
+}
+
+// DefaultOperations returns a registry holding the operations built into
+// this release
+func DefaultOperations() *OperationRegistry {
+	return NewOperationRegistry(defaultOperations...)
+}
+
+// LoadOperations returns the built-in operations updated with the JSON
+// file at path, see OperationRegistry.LoadFile
+func LoadOperations(path string) (*OperationRegistry, error) {
+	r := DefaultOperations()
+	if err := r.LoadFile(path); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Get returns a copy of the named operation
+func (r *OperationRegistry) Get(name string) (Operation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	op, ok := r.ops[name]
+	return op.clone(), ok
+}
+
+// Names returns the registered operation names in sorted order
+func (r *OperationRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.ops))
+	for name := range r.ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Set adds operations, or updates the registered operation of the same
+// name with the fields that are set (see Operation.merge). Guest access
+// can only be granted here; revoke it with LoadFile or UnmarshalJSON.
+func (r *OperationRegistry) Set(ops ...Operation) {
+	r.set(ops, nil)
+}
+
+// set is Set, with guest holding an explicit Guest value by operation name
+func (r *OperationRegistry) set(ops []Operation, guest map[string]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ops == nil {
+		r.ops = make(map[string]Operation, len(ops))
+	}
+	for _, op := range ops {
+		existing, ok := r.ops[op.Name]
+		if !ok {
+			existing = Operation{Name: op.Name, Method: "GET", Host: OperationHostWeb}
+		}
+		merged := existing.merge(op)
+		if allowed, ok := guest[op.Name]; ok {
+			merged.Guest = allowed
+		}
+		r.ops[op.Name] = merged
+	}
+}
+
+// LoadFile updates the registry from a JSON file, see UnmarshalJSON.
+// Operations missing from the file are left unchanged. Only JSON is
+// supported; convert YAML or other formats before loading.
+func (r *OperationRegistry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read operations file: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return fmt.Errorf("failed to parse operations file %s: %w", path, err)
+	}
+	return nil
+}
+
+// UnmarshalJSON updates the registry, see Set. Unlike Set, an explicit
+// "guest": false revokes guest access.
+func (r *OperationRegistry) UnmarshalJSON(data []byte) error {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	ops := make([]Operation, 0, len(entries))
+	guest := make(map[string]bool)
+	for name, entry := range entries {
+		var op Operation
+		if err := json.Unmarshal(entry, &op); err != nil {
+			return fmt.Errorf("operation %s: %w", name, err)
+		}
+		if op.Host != "" && op.Host != OperationHostWeb && op.Host != OperationHostAPI {
+			return fmt.Errorf("operation %s: unknown host %q", name, op.Host)
+		}
+
+		var fields struct {
+			Guest *bool `json:"guest"`
+		}
+		if err := json.Unmarshal(entry, &fields); err == nil && fields.Guest != nil {
+			guest[name] = *fields.Guest
+		}
+
+		op.Name = name
+		ops = append(ops, op)
+	}
+
+	r.set(ops, guest)
+	return nil
+}
+
+// MarshalJSON encodes the registry in the form accepted by LoadFile
+func (r *OperationRegistry) MarshalJSON() ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make(map[string]Operation, len(r.ops))
+	for name, op := range r.ops {
+		op.Name = ""
+		entries[name] = op
+	}
+	return json.Marshal(entries)
+}
+
+// Operation returns the named operation from Operations (or the built-in
+// operations if Operations is nil). Its query ID comes from QueryIDs first,
+// then a changed Constants.QueryID, then Operations.
+// Operations only listed in QueryIDs are returned as GET requests to the
+// web host without feature flags.
+func (c *Config) Operation(name string) (Operation, bool) {
+	registry := c.Operations
+	if registry == nil {
+		registry = builtinOperations
+	}
+
+	op, ok := registry.Get(name)
+	id := c.QueryIDs[name]
+	if id == "" {
+		id = c.Constants.queryID(name)
+	}
+	if !ok {
+		if id == "" {
+			return Operation{}, false
+		}
+		op = Operation{Name: name, Method: "GET", Host: OperationHostWeb}
+	}
+	if id != "" {
+		op.QueryID = id
+	}
+	return op, true
+}
+
+// GraphQLRequest builds the request for a GraphQL operation: method, URL,
+// operation name and, for POST operations, the JSON body. Callers add the
+// auth and page specific headers.
+//
+// Example:
+//
+//	reqConfig, err := config.GraphQLRequest("FavoriteTweet", map[string]any{"tweet_id": tweetID})
+func (c *Config) GraphQLRequest(name string, variables any) (utils.RequestConfig, error) {
+	op, ok := c.Operation(name)
+	if !ok {
+		return utils.RequestConfig{}, fmt.Errorf("unknown GraphQL operation %s", name)
+	}
+	return c.NewGraphQLRequest(op, variables)
+}
+
+// NewGraphQLRequest is like GraphQLRequest but takes the operation itself,
+// e.g. one returned by Operation and adjusted with WithFeatures
+func (c *Config) NewGraphQLRequest(op Operation, variables any) (utils.RequestConfig, error) {
+	name := op.Name
+	if name == "" {
+		return utils.RequestConfig{}, fmt.Errorf("GraphQL operation has no name")
+	}
+	if op.QueryID == "" {
+		return utils.RequestConfig{}, fmt.Errorf("GraphQL operation %s has no query ID", name)
+	}
+	op.Method = strings.ToUpper(op.Method)
+	if op.Method == "" {
+		op.Method = "GET"
+	}
+	if variables == nil {
+		variables = map[string]any{}
+	}
+
+	var endpoint string
+	switch op.Host {
+	case OperationHostAPI:
+		endpoint = c.URL(c.Hosts.WithDefaults().API, PathAPIGraphQL+"/"+op.QueryID+"/"+name)
+	default:
+		endpoint = c.GraphQLURL(op.QueryID, name)
+	}
+
+	reqConfig := c.NewRequest()
+	reqConfig.Method = op.Method
+	reqConfig.Operation = name
+
+	if op.Method == "GET" {
+		params := url.Values{}
+		if err := addJSONParam(params, "variables", variables); err != nil {
+			return utils.RequestConfig{}, fmt.Errorf("failed to encode %s variables: %w", name, err)
+		}
+		if len(op.Features) > 0 {
+			if err := addJSONParam(params, "features", op.Features); err != nil {
+				return utils.RequestConfig{}, fmt.Errorf("failed to encode %s features: %w", name, err)
+			}
+		}
+		if len(op.FieldToggles) > 0 {
+			if err := addJSONParam(params, "fieldToggles", op.FieldToggles); err != nil {
+				return utils.RequestConfig{}, fmt.Errorf("failed to encode %s field toggles: %w", name, err)
+			}
+		}
+		reqConfig.URL = endpoint + "?" + params.Encode()
+		return reqConfig, nil
+	}
+
+	body := map[string]any{
+		"variables": variables,
+		"queryId":   op.QueryID,
+	}
+	if len(op.Features) > 0 {
+		body["features"] = op.Features
+	}
+	if len(op.FieldToggles) > 0 {
+		body["fieldToggles"] = op.FieldToggles
+	}
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return utils.RequestConfig{}, fmt.Errorf("failed to encode %s request: %w", name, err)
+	}
+	reqConfig.URL = endpoint
+	reqConfig.Body = strings.NewReader(string(encoded))
+	return reqConfig, nil
+}
+
+func addJSONParam(params url.Values, key string, value any) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	params.Set(key, string(encoded))
+	return nil
+}
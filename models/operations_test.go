@@ -0,0 +1,126 @@
+package models_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+)
+
+func loadOperations(t *testing.T, registry *models.OperationRegistry, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "operations.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+}
+
+func TestLoadFileReplacesFlags(t *testing.T) {
+	registry := models.DefaultOperations()
+	loadOperations(t, registry, `{
+		"UserByScreenName": {"features": {"hidden_profile_subscriptions_enabled": false}},
+		"TweetDetail": {"fieldToggles": {}}
+	}`)
+
+	user, _ := registry.Get("UserByScreenName")
+	if len(user.Features) != 1 || user.Features["hidden_profile_subscriptions_enabled"] {
+		t.Errorf("UserByScreenName features = %v, want only the flags from the file", user.Features)
+	}
+	if !user.Guest || user.QueryID != models.QueryIDUserByScreenName {
+		t.Errorf("UserByScreenName = %+v, want fields missing from the file kept", user)
+	}
+
+	detail, _ := registry.Get("TweetDetail")
+	if len(detail.FieldToggles) != 0 {
+		t.Errorf("TweetDetail field toggles = %v, want them removed", detail.FieldToggles)
+	}
+	if len(detail.Features) == 0 {
+		t.Error("TweetDetail features were removed, want them kept")
+	}
+}
+
+func TestLoadFileGuest(t *testing.T) {
+	registry := models.DefaultOperations()
+	loadOperations(t, registry, `{
+		"TweetDetail": {"guest": true},
+		"FavoriteTweet": {"guest": true},
+		"UserByScreenName": {"queryId": "hotfixUserByScreenName"}
+	}`)
+	loadOperations(t, registry, `{"FavoriteTweet": {"guest": false}}`)
+
+	for name, want := range map[string]bool{
+		"TweetDetail":      true,
+		"FavoriteTweet":    false,
+		"UserByScreenName": true,
+	} {
+		if op, _ := registry.Get(name); op.Guest != want {
+			t.Errorf("%s guest = %v, want %v", name, op.Guest, want)
+		}
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	registry := models.DefaultOperations()
+	path := filepath.Join(t.TempDir(), "operations.json")
+	for _, content := range []string{
+		`FavoriteTweet: {queryId: abc}`,
+		`{"FavoriteTweet": {"host": "upload"}}`,
+		`{"FavoriteTweet": {"features": []}}`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.LoadFile(path); err == nil {
+			t.Errorf("LoadFile(%s) error = nil, want an error", content)
+		}
+	}
+	if err := registry.LoadFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadFile() of a missing file error = nil, want an error")
+	}
+}
+
+func TestWithFeaturesMergesFlags(t *testing.T) {
+	op, _ := models.DefaultOperations().Get("UserByScreenName")
+	changed := op.WithFeatures(map[string]bool{"hidden_profile_subscriptions_enabled": false, "new_flag": true})
+
+	if len(changed.Features) != len(op.Features)+1 {
+		t.Errorf("WithFeatures() has %d features, want %d", len(changed.Features), len(op.Features)+1)
+	}
+	if changed.Features["hidden_profile_subscriptions_enabled"] || !changed.Features["new_flag"] {
+		t.Errorf("WithFeatures() features = %v, want the given flags applied", changed.Features)
+	}
+	if !op.Features["hidden_profile_subscriptions_enabled"] {
+		t.Error("WithFeatures() changed the original operation")
+	}
+}
+
+func TestDeprecatedConstantsQueryID(t *testing.T) {
+	config := models.NewConfig()
+	if config.Constants.QueryID.Like != models.QueryIDLike {
+		t.Errorf("Constants.QueryID.Like = %q, want the built-in ID", config.Constants.QueryID.Like)
+	}
+
+	// Unchanged constants leave a registry hot-fix in place
+	config.Operations.Set(models.Operation{Name: "FavoriteTweet", QueryID: "hotfixFavoriteTweet000"})
+	if id := config.QueryIDFor("FavoriteTweet"); id != "hotfixFavoriteTweet000" {
+		t.Errorf("QueryIDFor(FavoriteTweet) = %q, want the registry hot-fix", id)
+	}
+
+	config.Constants.QueryID.Like = "constantFavoriteTweet0"
+	config.Constants.QueryID.Tweet = "constantCreateTweet000"
+	if id := config.QueryIDFor("FavoriteTweet"); id != "constantFavoriteTweet0" {
+		t.Errorf("QueryIDFor(FavoriteTweet) = %q, want the changed constant", id)
+	}
+	if id := config.QueryIDFor("CreateTweet"); id != "constantCreateTweet000" {
+		t.Errorf("QueryIDFor(CreateTweet) = %q, want the changed constant", id)
+	}
+
+	config.QueryIDs = map[string]string{"FavoriteTweet": "explicitFavoriteTweet0"}
+	if id := config.QueryIDFor("FavoriteTweet"); id != "explicitFavoriteTweet0" {
+		t.Errorf("QueryIDFor(FavoriteTweet) = %q, want Config.QueryIDs to win", id)
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// RateLimit is a snapshot of the x-rate-limit-* headers X sends for an endpoint
+type RateLimit struct {
+	Endpoint  string    // GraphQL operation name or REST path
+	Limit     int       // Requests allowed per window
+	Remaining int       // Requests left in the current window, see HasRemaining
+	Reset     time.Time // When the window resets
+	UpdatedAt time.Time // When the snapshot was taken
+
+	// HasRemaining is false if x-rate-limit-remaining was missing or
+	// malformed, in which case Remaining is 0 but says nothing
+	HasRemaining bool
+}
+
+// ParseRateLimit reads the rate-limit headers of a response.
+// It returns nil if the response carries none.
+func ParseRateLimit(endpoint string, header http.Header) *RateLimit {
+	limit, limitErr := strconv.Atoi(header.Get("x-rate-limit-limit"))
+	remaining, remainingErr := strconv.Atoi(header.Get("x-rate-limit-remaining"))
+	reset, resetErr := strconv.ParseInt(header.Get("x-rate-limit-reset"), 10, 64)
+	if limitErr != nil && remainingErr != nil && resetErr != nil {
+		return nil
+	}
+
+	rateLimit := &RateLimit{
+		Endpoint:     endpoint,
+		Limit:        limit,
+		Remaining:    remaining,
+		UpdatedAt:    time.Now(),
+		HasRemaining: remainingErr == nil,
+	}
+	if resetErr == nil {
+		rateLimit.Reset = time.Unix(reset, 0)
+	}
+	return rateLimit
+}
+
+// Exhausted reports whether no requests are left in the current window
+func (r *RateLimit) Exhausted() bool {
+	return r != nil && r.HasRemaining && r.Remaining <= 0 && time.Now().Before(r.Reset)
+}
+
+// ResetIn returns the time left until the window resets, or 0 if it already has
+func (r *RateLimit) ResetIn() time.Duration {
+	if r == nil {
+		return 0
+	}
+	if d := time.Until(r.Reset); d > 0 {
+		return d
+	}
+	return 0
+}
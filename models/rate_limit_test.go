@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+	http "github.com/bogdanfinn/fhttp"
+)
+
+func rateLimitHeader(limit, remaining, reset string) http.Header {
+	header := http.Header{}
+	for key, value := range map[string]string{
+		"x-rate-limit-limit":     limit,
+		"x-rate-limit-remaining": remaining,
+		"x-rate-limit-reset":     reset,
+	} {
+		if value != "" {
+			header.Set(key, value)
+		}
+	}
+	return header
+}
+
+// unix formats t like x-rate-limit-reset
+func unix(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		name                    string
+		limit, remaining, reset string
+		want                    *models.RateLimit // Reset in Unix seconds, UpdatedAt ignored
+	}{
+		{"missing", "", "", "", nil},
+		{"malformed", "many", "some", "soon", nil},
+		{"complete", "50", "49", "1700000000", &models.RateLimit{Limit: 50, Remaining: 49, Reset: time.Unix(1700000000, 0), HasRemaining: true}},
+		{"exhausted", "50", "0", "1700000000", &models.RateLimit{Limit: 50, Remaining: 0, Reset: time.Unix(1700000000, 0), HasRemaining: true}},
+		{"only limit", "50", "", "", &models.RateLimit{Limit: 50}},
+		{"only remaining", "", "3", "", &models.RateLimit{Remaining: 3, HasRemaining: true}},
+		{"only reset", "", "", "1700000000", &models.RateLimit{Reset: time.Unix(1700000000, 0)}},
+		{"malformed remaining", "50", "n/a", "1700000000", &models.RateLimit{Limit: 50, Reset: time.Unix(1700000000, 0)}},
+		{"malformed reset", "50", "10", "1700000000.5", &models.RateLimit{Limit: 50, Remaining: 10, HasRemaining: true}},
+		{"reset in exponent form", "50", "10", "1.7e9", &models.RateLimit{Limit: 50, Remaining: 10, HasRemaining: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			got := models.ParseRateLimit("FavoriteTweet", rateLimitHeader(tt.limit, tt.remaining, tt.reset))
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("ParseRateLimit() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("ParseRateLimit() = nil")
+			}
+			if got.Endpoint != "FavoriteTweet" || got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining ||
+				got.HasRemaining != tt.want.HasRemaining || !got.Reset.Equal(tt.want.Reset) {
+				t.Errorf("ParseRateLimit() = %+v, want %+v", got, tt.want)
+			}
+			if got.UpdatedAt.Before(before) {
+				t.Errorf("UpdatedAt = %v, want the time of parsing", got.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestRateLimitReset(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	rateLimit := models.ParseRateLimit("FavoriteTweet", rateLimitHeader("50", "0", unix(reset)))
+	if !rateLimit.Reset.Equal(reset) || rateLimit.Reset.Location() != time.Local {
+		t.Errorf("Reset = %v, want %v in local time", rateLimit.Reset, reset)
+	}
+	if !rateLimit.Exhausted() {
+		t.Error("Exhausted() = false with none remaining before the reset")
+	}
+	if d := rateLimit.ResetIn(); d <= 0 || d > time.Minute {
+		t.Errorf("ResetIn() = %v, want up to a minute", d)
+	}
+
+	past := models.ParseRateLimit("FavoriteTweet", rateLimitHeader("50", "0", unix(time.Now().Add(-time.Minute))))
+	if past.Exhausted() || past.ResetIn() != 0 {
+		t.Errorf("past reset: Exhausted() = %v, ResetIn() = %v, want false and 0", past.Exhausted(), past.ResetIn())
+	}
+
+	unknown := models.ParseRateLimit("FavoriteTweet", rateLimitHeader("50", "", unix(reset)))
+	if unknown.Exhausted() {
+		t.Error("Exhausted() = true without x-rate-limit-remaining")
+	}
+
+	var none *models.RateLimit
+	if none.Exhausted() || none.ResetIn() != 0 {
+		t.Error("nil RateLimit is exhausted")
+	}
+}
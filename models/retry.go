@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// RetryPolicy controls how the request layer retries failed calls.
+//
+// Requests that are not idempotent (such as CreateTweet or media uploads)
+// are only retried when X certainly did not process them, i.e. after a
+// rate-limit rejection, unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first; 1 or less disables retries
+	BaseDelay   time.Duration // Delay before the first retry, doubled on every further one
+	MaxDelay    time.Duration // Upper bound for a single delay
+	Jitter      float64       // Fraction (0-1) of each delay that is randomized
+
+	RetryStatuses      []int   // HTTP statuses worth retrying
+	RetryErrors        []error // Errors (matched with errors.Is) worth retrying
+	RetryNetworkErrors bool    // Retry when no response was received at all
+	RetryNonIdempotent bool    // Also retry requests that are not safe to repeat
+}
+
+// DefaultRetryPolicy returns the policy used by NewConfig: three attempts
+// with exponential backoff on network errors, 5xx and rate limits
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		RetryStatuses: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryErrors:        []error{ErrRateLimited},
+		RetryNetworkErrors: true,
+	}
+}
+
+// Delay returns how long to wait before the given retry (1 for the first retry)
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	if retry < 1 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		jitter := min(p.Jitter, 1)
+		delay = time.Duration(float64(delay) * (1 - jitter + 2*jitter*rand.Float64()))
+	}
+	return delay
+}
+
+// ShouldRetry reports whether a failed attempt is worth repeating.
+// statusCode is 0 when no response was received. Daily limits
+// (ErrDailyLimit) are final whatever the status code.
+func (p RetryPolicy) ShouldRetry(idempotent bool, statusCode int, err error) bool {
+	if errors.Is(err, ErrDailyLimit) {
+		return false
+	}
+	rateLimited := statusCode == http.StatusTooManyRequests || errors.Is(err, ErrRateLimited)
+	if !idempotent && !p.RetryNonIdempotent && !rateLimited {
+		return false
+	}
+
+	if statusCode == 0 {
+		return p.RetryNetworkErrors && err != nil
+	}
+	for _, status := range p.RetryStatuses {
+		if status == statusCode {
+			return true
+		}
+	}
+	for _, target := range p.RetryErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
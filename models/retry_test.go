@@ -0,0 +1,105 @@
+package models_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := models.RetryPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	for retry, want := range map[int]time.Duration{
+		-1:  0,
+		0:   0,
+		1:   time.Second,
+		2:   2 * time.Second,
+		3:   4 * time.Second,
+		4:   8 * time.Second,
+		5:   10 * time.Second,
+		6:   10 * time.Second,
+		100: 10 * time.Second,
+	} {
+		if got := policy.Delay(retry); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", retry, got, want)
+		}
+	}
+
+	uncapped := models.RetryPolicy{BaseDelay: time.Millisecond}
+	if got := uncapped.Delay(11); got != 1024*time.Millisecond {
+		t.Errorf("Delay(11) without MaxDelay = %v, want 1.024s", got)
+	}
+	if got := (models.RetryPolicy{MaxDelay: time.Second}).Delay(3); got != 0 {
+		t.Errorf("Delay(3) without BaseDelay = %v, want 0", got)
+	}
+}
+
+func TestRetryDelayJitter(t *testing.T) {
+	tests := []struct {
+		jitter   float64
+		min, max time.Duration
+	}{
+		{0.2, 3200 * time.Millisecond, 4800 * time.Millisecond},
+		{1, 0, 8 * time.Second},
+		{5, 0, 8 * time.Second}, // capped at 1
+	}
+	for _, tt := range tests {
+		policy := models.RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: tt.jitter}
+		varied := false
+		for i := 0; i < 1000; i++ {
+			delay := policy.Delay(3)
+			if delay < tt.min || delay > tt.max {
+				t.Fatalf("jitter %v: Delay(3) = %v, want within [%v, %v]", tt.jitter, delay, tt.min, tt.max)
+			}
+			varied = varied || delay != 4*time.Second
+		}
+		if !varied {
+			t.Errorf("jitter %v: Delay(3) is always 4s", tt.jitter)
+		}
+	}
+}
+
+func TestRetryShouldRetry(t *testing.T) {
+	policy := models.DefaultRetryPolicy()
+	transport := errors.New("connection reset by peer")
+	rateLimited := fmt.Errorf("wrapped: %w", &models.APIError{StatusCode: 200, Code: 88, Err: models.ErrRateLimited})
+	dailyLimit := &models.APIError{StatusCode: 429, Code: 344, Err: models.ErrDailyLimit}
+	csrf := &models.APIError{StatusCode: 403, Code: 353, Err: models.ErrBadCSRF}
+
+	tests := []struct {
+		name       string
+		idempotent bool
+		statusCode int
+		err        error
+		want       bool
+	}{
+		{"transport error", true, 0, transport, true},
+		{"transport error, not idempotent", false, 0, transport, false},
+		{"no response and no error", true, 0, nil, false},
+		{"server error", true, 503, &models.APIError{StatusCode: 503}, true},
+		{"server error, not idempotent", false, 503, &models.APIError{StatusCode: 503}, false},
+		{"429", true, 429, &models.APIError{StatusCode: 429, Err: models.ErrRateLimited}, true},
+		{"429, not idempotent", false, 429, &models.APIError{StatusCode: 429, Err: models.ErrRateLimited}, true},
+		{"rate limit code on 200", false, 200, rateLimited, true},
+		{"daily limit", true, 429, dailyLimit, false},
+		{"daily limit, not idempotent", false, 429, dailyLimit, false},
+		{"client error", true, 403, csrf, false},
+		{"not found", true, 404, &models.APIError{StatusCode: 404, Err: models.ErrNotFound}, false},
+	}
+	for _, tt := range tests {
+		if got := policy.ShouldRetry(tt.idempotent, tt.statusCode, tt.err); got != tt.want {
+			t.Errorf("%s: ShouldRetry() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	policy.RetryNonIdempotent = true
+	if !policy.ShouldRetry(false, 0, transport) || !policy.ShouldRetry(false, 503, nil) {
+		t.Error("RetryNonIdempotent does not retry requests that are not idempotent")
+	}
+	policy.RetryNetworkErrors = false
+	if policy.ShouldRetry(true, 0, transport) {
+		t.Error("transport error retried without RetryNetworkErrors")
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SessionVersion is the format version written by EncodeSession
+const SessionVersion = 1
+
+// sessionCipher names the encryption used by EncodeSession
+const sessionCipher = "AES-GCM"
+
+// Session is everything needed to resume a signed-in client without
+// running the initialization requests again, see Twitter.ExportSession
+type Session struct {
+	Version   int             `json:"version"`
+	Username  string          `json:"username"`
+	UserID    string          `json:"userId,omitempty"`
+	AuthToken string          `json:"authToken"`
+	Ct0       string          `json:"ct0"`
+	Proxy     string          `json:"proxy,omitempty"`
+	Cookies   []SessionCookie `json:"cookies"`
+
+	CreatedAt   time.Time `json:"createdAt"`   // When the session was first validated
+	ValidatedAt time.Time `json:"validatedAt"` // Last successful Viewer check
+	ExportedAt  time.Time `json:"exportedAt"`
+}
+
+// SessionCookie is a cookie as stored in a Session
+type SessionCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain,omitempty"`
+	Path     string     `json:"path,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"` // Nil for session cookies
+	Secure   bool       `json:"secure,omitempty"`
+	HttpOnly bool       `json:"httpOnly,omitempty"`
+}
+
+// sessionEnvelope is the encrypted form of a Session
+type sessionEnvelope struct {
+	Version    int    `json:"version"`
+	Cipher     string `json:"cipher"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncodeSession encodes s as JSON. With a key (16, 24 or 32 bytes for
+// AES-128, AES-192 or AES-256) the JSON is sealed with AES-GCM, so the
+// output can be stored where the cookies must not be readable.
+func EncodeSession(s *Session, key []byte) ([]byte, error) {
+	session := *s
+	session.Version = SessionVersion
+	data, err := json.Marshal(&session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session: %w", err)
+	}
+	if key == nil {
+		return data, nil
+	}
+
+	aead, err := sessionAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return json.Marshal(sessionEnvelope{
+		Version:    SessionVersion,
+		Cipher:     sessionCipher,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, data, nil),
+	})
+}
+
+// DecodeSession decodes a session written by EncodeSession. Encrypted
+// sessions need the key they were encrypted with; a missing or wrong key
+// fails with ErrSessionKey. So does a key given for a session that is not
+// encrypted, as it may have been replaced.
+func DecodeSession(data, key []byte) (*Session, error) {
+	var envelope sessionEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	if envelope.Ciphertext != nil {
+		if envelope.Cipher != sessionCipher {
+			return nil, fmt.Errorf("unsupported session cipher %q", envelope.Cipher)
+		}
+		if key == nil {
+			return nil, fmt.Errorf("%w: session is encrypted", ErrSessionKey)
+		}
+		aead, err := sessionAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(envelope.Nonce) != aead.NonceSize() {
+			return nil, fmt.Errorf("failed to decode session: bad nonce")
+		}
+		if data, err = aead.Open(nil, envelope.Nonce, envelope.Ciphertext, nil); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSessionKey, err)
+		}
+	} else if key != nil {
+		return nil, fmt.Errorf("%w: session is not encrypted", ErrSessionKey)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if session.Version > SessionVersion {
+		return nil, fmt.Errorf("session version %d is newer than supported version %d", session.Version, SessionVersion)
+	}
+	if session.AuthToken == "" {
+		return nil, fmt.Errorf("failed to decode session: no auth token")
+	}
+	return &session, nil
+}
+
+func sessionAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSessionKey, err)
+	}
+	return cipher.NewGCM(block)
+}
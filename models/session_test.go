@@ -0,0 +1,93 @@
+package models_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+)
+
+func testSession() *models.Session {
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	return &models.Session{
+		Username:  "alice",
+		AuthToken: "alice-auth-token",
+		Ct0:       "alice-ct0",
+		Cookies: []models.SessionCookie{
+			{Name: "auth_token", Value: "alice-auth-token", Domain: ".x.com", Path: "/", Expires: &expires, Secure: true, HttpOnly: true},
+			{Name: "ct0", Value: "alice-ct0", Domain: ".x.com", Path: "/"},
+		},
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	for _, key := range [][]byte{nil, key} {
+		data, err := models.EncodeSession(testSession(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != nil && bytes.Contains(data, []byte("alice-auth-token")) {
+			t.Error("encrypted session contains the auth token")
+		}
+
+		session, err := models.DecodeSession(data, key)
+		if err != nil {
+			t.Fatalf("DecodeSession() error = %v", err)
+		}
+		if session.Version != models.SessionVersion || session.AuthToken != "alice-auth-token" || len(session.Cookies) != 2 {
+			t.Errorf("DecodeSession() = %+v", session)
+		}
+		if expires := session.Cookies[0].Expires; expires == nil || !expires.Equal(*testSession().Cookies[0].Expires) {
+			t.Errorf("auth_token expires = %v", expires)
+		}
+		if session.Cookies[1].Expires != nil {
+			t.Errorf("ct0 expires = %v, want a session cookie", session.Cookies[1].Expires)
+		}
+	}
+}
+
+func TestSessionCookieWithoutExpiry(t *testing.T) {
+	data, err := models.EncodeSession(testSession(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), `"expires"`); n != 1 {
+		t.Errorf("encoded session has %d expires fields, want 1: %s", n, data)
+	}
+	ct0 := string(data[bytes.Index(data, []byte(`{"name":"ct0"`)):])
+	if cookie, _, _ := strings.Cut(ct0, "}"); strings.Contains(cookie, "expires") {
+		t.Errorf("ct0 cookie has an expiry: %s", cookie)
+	}
+}
+
+func TestDecodeSessionKeyErrors(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	plain, err := models.EncodeSession(testSession(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := models.EncodeSession(testSession(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		key  []byte
+	}{
+		{"plaintext with key", plain, key},
+		{"encrypted without key", encrypted, nil},
+		{"encrypted with wrong key", encrypted, bytes.Repeat([]byte{8}, 32)},
+		{"encrypted with invalid key", encrypted, []byte("short")},
+	}
+	for _, tt := range tests {
+		if _, err := models.DecodeSession(tt.data, tt.key); !errors.Is(err, models.ErrSessionKey) {
+			t.Errorf("%s: DecodeSession() error = %v, want ErrSessionKey", tt.name, err)
+		}
+	}
+}
@@ -30,23 +30,7 @@ type TweetGraphQLResponse struct {
 			} `json:"tweet_results"`
 		} `json:"create_tweet"`
 	} `json:"data"`
-	Errors []struct {
-		Message   string `json:"message"`
-		Locations []struct {
-			Line   int `json:"line"`
-			Column int `json:"column"`
-		} `json:"locations"`
-		Path       []string `json:"path"`
-		Extensions struct {
-			Name    string `json:"name"`
-			Source  string `json:"source"`
-			Code    int    `json:"code"`
-			Kind    string `json:"kind"`
-			Tracing struct {
-				TraceID string `json:"trace_id"`
-			} `json:"tracing"`
-		} `json:"extensions"`
-	} `json:"errors"`
+	Errors []GraphQLError `json:"errors"`
 }
 
 // MediaUploadResponse represents the response from media upload
@@ -70,23 +54,7 @@ type RetweetGraphQLResponse struct {
 			} `json:"retweet_results"`
 		} `json:"create_retweet"`
 	} `json:"data"`
-	Errors []struct {
-		Message   string `json:"message"`
-		Locations []struct {
-			Line   int `json:"line"`
-			Column int `json:"column"`
-		} `json:"locations"`
-		Path       []string `json:"path"`
-		Extensions struct {
-			Name    string `json:"name"`
-			Source  string `json:"source"`
-			Code    int    `json:"code"`
-			Kind    string `json:"kind"`
-			Tracing struct {
-				TraceID string `json:"trace_id"`
-			} `json:"tracing"`
-		} `json:"extensions"`
-	} `json:"errors"`
+	Errors []GraphQLError `json:"errors"`
 }
 
 // UnretweetGraphQLResponse represents the GraphQL response for an unretweet action
@@ -103,21 +71,5 @@ type UnretweetGraphQLResponse struct {
 			} `json:"source_tweet_results"`
 		} `json:"unretweet"`
 	} `json:"data"`
-	Errors []struct {
-		Message   string `json:"message"`
-		Locations []struct {
-			Line   int `json:"line"`
-			Column int `json:"column"`
-		} `json:"locations"`
-		Path       []string `json:"path"`
-		Extensions struct {
-			Name    string `json:"name"`
-			Source  string `json:"source"`
-			Code    int    `json:"code"`
-			Kind    string `json:"kind"`
-			Tracing struct {
-				TraceID string `json:"trace_id"`
-			} `json:"tracing"`
-		} `json:"extensions"`
-	} `json:"errors"`
+	Errors []GraphQLError `json:"errors"`
 }
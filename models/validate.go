@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	idPattern       = regexp.MustCompile(`^[0-9]{1,19}$`)
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+)
+
+// ValidateTweetID checks that id is a numeric tweet ID. User IDs have the
+// same format and are checked with ValidateUserID.
+func ValidateTweetID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: tweet ID %q must be 1 to 19 digits", ErrInvalidInput, id)
+	}
+	return nil
+}
+
+// ValidateUserID checks that id is a numeric user ID
+func ValidateUserID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: user ID %q must be 1 to 19 digits", ErrInvalidInput, id)
+	}
+	return nil
+}
+
+// ValidateUsername checks that username is a screen name of 1 to 15
+// letters, digits or underscores, without the leading @
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("%w: invalid username %q", ErrInvalidInput, username)
+	}
+	return nil
+}
+
+// NormalizeUsername strips surrounding spaces and a leading @ and
+// validates the result
+func NormalizeUsername(username string) (string, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if err := ValidateUsername(username); err != nil {
+		return "", err
+	}
+	return username, nil
+}
+
+// ValidatePollChoice checks that choice is a 1-based poll option number
+func ValidatePollChoice(choice string) error {
+	switch choice {
+	case "1", "2", "3", "4":
+		return nil
+	}
+	return fmt.Errorf("%w: poll choice %q must be 1 to 4", ErrInvalidInput, choice)
+}
@@ -0,0 +1,88 @@
+package models_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+)
+
+func TestValidateTweetID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"1", true},
+		{"1234567890123456789", true},
+		{"0000000000000000001", true},
+		{"", false},
+		{"12345678901234567890", false}, // 20 digits
+		{"123abc", false},
+		{"-123", false},
+		{"+123", false},
+		{"1.5", false},
+		{" 123", false},
+		{"123\n", false},
+		{"123&x=1", false},
+		{`123","dark_request":true`, false},
+		{"１２３", false}, // fullwidth digits
+	}
+	for _, tt := range tests {
+		err := models.ValidateTweetID(tt.id)
+		if tt.valid && err != nil {
+			t.Errorf("ValidateTweetID(%q) error = %v", tt.id, err)
+		}
+		if !tt.valid && !errors.Is(err, models.ErrInvalidInput) {
+			t.Errorf("ValidateTweetID(%q) error = %v, want ErrInvalidInput", tt.id, err)
+		}
+		if userErr := models.ValidateUserID(tt.id); (userErr == nil) != tt.valid {
+			t.Errorf("ValidateUserID(%q) error = %v, want valid %v", tt.id, userErr, tt.valid)
+		}
+	}
+}
+
+func TestNormalizeUsername(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // empty if invalid
+	}{
+		{"elonmusk", "elonmusk"},
+		{"@elonmusk", "elonmusk"},
+		{"  @Elon_Musk ", "Elon_Musk"},
+		{"\telonmusk\n", "elonmusk"},
+		{"a", "a"},
+		{"abcdefghijklmno", "abcdefghijklmno"},
+		{"abcdefghijklmnop", ""}, // 16 characters
+		{"", ""},
+		{"@", ""},
+		{"@@elonmusk", ""},
+		{"elon musk", ""},
+		{"elon-musk", ""},
+		{"elon\nmusk", ""},
+		{"elonmusk&x=1", ""},
+		{`elonmusk","screen_name":"other`, ""},
+		{"élon", ""},
+	}
+	for _, tt := range tests {
+		got, err := models.NormalizeUsername(tt.input)
+		if tt.want == "" {
+			if !errors.Is(err, models.ErrInvalidInput) || got != "" {
+				t.Errorf("NormalizeUsername(%q) = %q, %v, want ErrInvalidInput", tt.input, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeUsername(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidatePollChoice(t *testing.T) {
+	for choice, valid := range map[string]bool{
+		"1": true, "4": true, "0": false, "5": false, "": false, "01": false, "1 ": false,
+	} {
+		if err := models.ValidatePollChoice(choice); (err == nil) != valid {
+			t.Errorf("ValidatePollChoice(%q) error = %v, want valid %v", choice, err, valid)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package models
+
+// GraphQL variables of the operations in defaultOperations. They are
+// encoded with encoding/json, so user input can never change the shape
+// of a request.
+
+// TweetVariables are the variables of FavoriteTweet and UnfavoriteTweet
+type TweetVariables struct {
+	TweetID string `json:"tweet_id"`
+}
+
+// RetweetVariables are the variables of CreateRetweet
+type RetweetVariables struct {
+	TweetID     string `json:"tweet_id"`
+	DarkRequest bool   `json:"dark_request"`
+}
+
+// CreateTweetVariables are the variables of CreateTweet, used for tweets,
+// quotes and replies
+type CreateTweetVariables struct {
+	TweetText             string      `json:"tweet_text"`
+	Reply                 *TweetReply `json:"reply,omitempty"`
+	AttachmentURL         string      `json:"attachment_url,omitempty"` // Quoted tweet URL
+	Media                 TweetMedia  `json:"media"`
+	DarkRequest           bool        `json:"dark_request"`
+	SemanticAnnotationIDs []string    `json:"semantic_annotation_ids"`
+}
+
+// TweetReply marks a CreateTweet as a reply
+type TweetReply struct {
+	InReplyToTweetID    string   `json:"in_reply_to_tweet_id"`
+	ExcludeReplyUserIDs []string `json:"exclude_reply_user_ids"`
+}
+
+// TweetMedia lists the uploaded media attached to a CreateTweet
+type TweetMedia struct {
+	MediaEntities     []MediaEntity `json:"media_entities"`
+	PossiblySensitive bool          `json:"possibly_sensitive"`
+}
+
+// MediaEntity is one uploaded media item, see Twitter.UploadMedia
+type MediaEntity struct {
+	MediaID     string   `json:"media_id"`
+	TaggedUsers []string `json:"tagged_users"`
+}
+
+// NewCreateTweetVariables returns the variables for a tweet with the
+// given text and optional media ID, with every list initialized as X expects
+func NewCreateTweetVariables(text, mediaID string) CreateTweetVariables {
+	media := TweetMedia{MediaEntities: []MediaEntity{}}
+	if mediaID != "" {
+		media.MediaEntities = append(media.MediaEntities, MediaEntity{MediaID: mediaID, TaggedUsers: []string{}})
+	}
+	return CreateTweetVariables{
+		TweetText:             text,
+		Media:                 media,
+		SemanticAnnotationIDs: []string{},
+	}
+}
+
+// TweetDetailVariables are the variables of TweetDetail
+type TweetDetailVariables struct {
+	FocalTweetID                           string `json:"focalTweetId"`
+	WithRuxInjections                      bool   `json:"with_rux_injections"`
+	IncludePromotedContent                 bool   `json:"includePromotedContent"`
+	WithCommunity                          bool   `json:"withCommunity"`
+	WithQuickPromoteEligibilityTweetFields bool   `json:"withQuickPromoteEligibilityTweetFields"`
+	WithBirdwatchNotes                     bool   `json:"withBirdwatchNotes"`
+	WithVoice                              bool   `json:"withVoice"`
+	WithV2Timeline                         bool   `json:"withV2Timeline"`
+}
+
+// UserByScreenNameVariables are the variables of UserByScreenName
+type UserByScreenNameVariables struct {
+	ScreenName string `json:"screen_name"`
+}
+
+// ViewerVariables are the variables of Viewer
+type ViewerVariables struct {
+	WithCommunitiesMemberships bool `json:"withCommunitiesMemberships"`
+}
@@ -0,0 +1,195 @@
+package models_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/models"
+)
+
+// hostileInputs try to break out of the value they are encoded into
+var hostileInputs = []string{
+	`"`,
+	`a"b`,
+	`\`,
+	`a&b=c`,
+	`=`,
+	"line1\nline2\r\n",
+	`","dark_request":true,"x":"`,
+	`"},"features":{"injected":true},"x":{"`,
+	`}}]`,
+	`<script>alert(1)</script>`,
+	"\x00 ",
+	"12345678901234567890123",
+	"not-a-number",
+}
+
+func keys(m map[string]any) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// postPayload returns the decoded JSON body of a POST GraphQL request
+func postPayload(t *testing.T, name string, variables any) map[string]any {
+	t.Helper()
+	reqConfig, err := models.NewConfig().GraphQLRequest(name, variables)
+	if err != nil {
+		t.Fatalf("GraphQLRequest(%s) error = %v", name, err)
+	}
+	if reqConfig.Method != "POST" || strings.Contains(reqConfig.URL, "?") {
+		t.Fatalf("GraphQLRequest(%s) = %s %s, want a POST without query", name, reqConfig.Method, reqConfig.URL)
+	}
+	body, err := io.ReadAll(reqConfig.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("GraphQLRequest(%s) body %s: %v", name, body, err)
+	}
+	return payload
+}
+
+// getQuery returns the query parameters of a GET GraphQL request
+func getQuery(t *testing.T, name string, variables any) url.Values {
+	t.Helper()
+	reqConfig, err := models.NewConfig().GraphQLRequest(name, variables)
+	if err != nil {
+		t.Fatalf("GraphQLRequest(%s) error = %v", name, err)
+	}
+	if reqConfig.Method != "GET" || reqConfig.Body != nil {
+		t.Fatalf("GraphQLRequest(%s) = %s with body, want a GET", name, reqConfig.Method)
+	}
+	u, err := url.Parse(reqConfig.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(u.Path, "/"+name) {
+		t.Errorf("GraphQLRequest(%s) path = %s", name, u.Path)
+	}
+	return u.Query()
+}
+
+func TestTweetVariablesEncoding(t *testing.T) {
+	for _, input := range hostileInputs {
+		payload := postPayload(t, "FavoriteTweet", models.TweetVariables{TweetID: input})
+		if got := keys(payload); !reflect.DeepEqual(got, []string{"queryId", "variables"}) {
+			t.Errorf("FavoriteTweet(%q) payload keys = %v", input, got)
+		}
+		if payload["queryId"] != models.QueryIDLike {
+			t.Errorf("FavoriteTweet(%q) queryId = %v", input, payload["queryId"])
+		}
+		want := map[string]any{"tweet_id": input}
+		if got := payload["variables"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("FavoriteTweet(%q) variables = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestRetweetVariablesEncoding(t *testing.T) {
+	for _, input := range hostileInputs {
+		payload := postPayload(t, "CreateRetweet", models.RetweetVariables{TweetID: input})
+		want := map[string]any{"tweet_id": input, "dark_request": false}
+		if got := payload["variables"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("CreateRetweet(%q) variables = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestCreateTweetVariablesEncoding(t *testing.T) {
+	for _, input := range hostileInputs {
+		variables := models.NewCreateTweetVariables(input, input)
+		variables.Reply = &models.TweetReply{InReplyToTweetID: input, ExcludeReplyUserIDs: []string{}}
+		variables.AttachmentURL = input
+
+		payload := postPayload(t, "CreateTweet", variables)
+		if got := keys(payload); !reflect.DeepEqual(got, []string{"features", "queryId", "variables"}) {
+			t.Errorf("CreateTweet(%q) payload keys = %v", input, got)
+		}
+		features, _ := payload["features"].(map[string]any)
+		if _, injected := features["injected"]; injected {
+			t.Errorf("CreateTweet(%q) injected a feature flag", input)
+		}
+
+		got, _ := payload["variables"].(map[string]any)
+		wantKeys := []string{"attachment_url", "dark_request", "media", "reply", "semantic_annotation_ids", "tweet_text"}
+		if !reflect.DeepEqual(keys(got), wantKeys) {
+			t.Errorf("CreateTweet(%q) variable keys = %v, want %v", input, keys(got), wantKeys)
+		}
+		if got["tweet_text"] != input || got["attachment_url"] != input || got["dark_request"] != false {
+			t.Errorf("CreateTweet(%q) variables = %v", input, got)
+		}
+		reply, _ := got["reply"].(map[string]any)
+		if len(reply) != 2 || reply["in_reply_to_tweet_id"] != input {
+			t.Errorf("CreateTweet(%q) reply = %v", input, reply)
+		}
+		media, _ := got["media"].(map[string]any)
+		entities, _ := media["media_entities"].([]any)
+		if len(media) != 2 || len(entities) != 1 || entities[0].(map[string]any)["media_id"] != input {
+			t.Errorf("CreateTweet(%q) media = %v", input, media)
+		}
+	}
+}
+
+func TestCreateTweetVariablesDefaults(t *testing.T) {
+	encoded, err := json.Marshal(models.NewCreateTweetVariables("hello", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"tweet_text":"hello","media":{"media_entities":[],"possibly_sensitive":false},"dark_request":false,"semantic_annotation_ids":[]}`
+	if string(encoded) != want {
+		t.Errorf("NewCreateTweetVariables() = %s, want %s", encoded, want)
+	}
+}
+
+func TestGETVariablesEncoding(t *testing.T) {
+	for _, input := range hostileInputs {
+		tests := []struct {
+			name      string
+			variables any
+			params    []string
+			field     string
+		}{
+			{"UserByScreenName", models.UserByScreenNameVariables{ScreenName: input}, []string{"features", "fieldToggles", "variables"}, "screen_name"},
+			{"TweetDetail", models.TweetDetailVariables{FocalTweetID: input}, []string{"features", "fieldToggles", "variables"}, "focalTweetId"},
+		}
+		for _, tt := range tests {
+			query := getQuery(t, tt.name, tt.variables)
+
+			params := make([]string, 0, len(query))
+			for param, values := range query {
+				if len(values) != 1 {
+					t.Errorf("%s(%q) has %d %s parameters", tt.name, input, len(values), param)
+				}
+				params = append(params, param)
+			}
+			sort.Strings(params)
+			if !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("%s(%q) parameters = %v, want %v", tt.name, input, params, tt.params)
+			}
+
+			var variables map[string]any
+			if err := json.Unmarshal([]byte(query.Get("variables")), &variables); err != nil {
+				t.Fatalf("%s(%q) variables: %v", tt.name, input, err)
+			}
+			if variables[tt.field] != input {
+				t.Errorf("%s(%q) %s = %v", tt.name, input, tt.field, variables[tt.field])
+			}
+			wantVariables, _ := json.Marshal(tt.variables)
+			var want map[string]any
+			json.Unmarshal(wantVariables, &want)
+			if !reflect.DeepEqual(keys(variables), keys(want)) {
+				t.Errorf("%s(%q) variable keys = %v, want %v", tt.name, input, keys(variables), keys(want))
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package twitter_utils
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// GraphQLOperation is a GraphQL operation declared in the X web client bundle
+type GraphQLOperation struct {
+	QueryID         string   `json:"queryId"`
+	OperationName   string   `json:"operationName"`
+	OperationType   string   `json:"operationType"` // "query" or "mutation"
+	FeatureSwitches []string `json:"featureSwitches,omitempty"`
+	FieldToggles    []string `json:"fieldToggles,omitempty"`
+}
+
+var (
+	// Script URLs of the bundles that declare GraphQL operations
+	bundleURLPattern = regexp.MustCompile(`["']([^"'\s]*/(?:main|api)\.[0-9A-Za-z]+\.js)["']`)
+
+	// {queryId:"...",operationName:"...",operationType:"...",metadata:{...}}
+	operationPattern = regexp.MustCompile(`queryId:\s*"([^"]+)",\s*operationName:\s*"([^"]+)",\s*operationType:\s*"([^"]+)"(?:,\s*metadata:\s*\{([^{}]*)\})?`)
+	featurePattern   = regexp.MustCompile(`featureSwitches:\s*\[([^\]]*)\]`)
+	togglePattern    = regexp.MustCompile(`fieldToggles:\s*\[([^\]]*)\]`)
+	stringPattern    = regexp.MustCompile(`"([^"]*)"`)
+)
+
+/*
+	ParseBundleURLs returns the main.*.js and api.*.js script URLs referenced
+	by the X web app HTML, resolved against pageURL and without duplicates.
+
+Example:
+
+	urls := ParseBundleURLs(html, "https://x.com/")
+	// urls = ["https://abs.twimg.com/responsive-web/client-web/main.8f1d2c3a.js"]
+*/
+func ParseBundleURLs(html string, pageURL string) []string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		base = &url.URL{}
+	}
+
+	seen := make(map[string]bool)
+	var urls []string
+	for _, match := range bundleURLPattern.FindAllStringSubmatch(html, -1) {
+		ref, err := url.Parse(match[1])
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(ref).String()
+		if !seen[resolved] {
+			seen[resolved] = true
+			urls = append(urls, resolved)
+		}
+	}
+	return urls
+}
+
+/*
+	ParseGraphQLOperations extracts the GraphQL operations declared in a web
+	client bundle, keyed by operation name. Later declarations of the same
+	operation win.
+
+Example:
+
+	ops := ParseGraphQLOperations(`e.exports={queryId:"lI07N6Otwv1PhnEgXILM7A",operationName:"FavoriteTweet",operationType:"mutation",metadata:{featureSwitches:[],fieldToggles:[]}}`)
+	// ops["FavoriteTweet"].QueryID = "lI07N6Otwv1PhnEgXILM7A"
+*/
+func ParseGraphQLOperations(js string) map[string]GraphQLOperation {
+	ops := make(map[string]GraphQLOperation)
+	for _, match := range operationPattern.FindAllStringSubmatch(js, -1) {
+		op := GraphQLOperation{
+			QueryID:       match[1],
+			OperationName: match[2],
+			OperationType: match[3],
+		}
+		if metadata := match[4]; metadata != "" {
+			if m := featurePattern.FindStringSubmatch(metadata); m != nil {
+				op.FeatureSwitches = parseStringList(m[1])
+			}
+			if m := togglePattern.FindStringSubmatch(metadata); m != nil {
+				op.FieldToggles = parseStringList(m[1])
+			}
+		}
+		ops[op.OperationName] = op
+	}
+	return ops
+}
+
+// parseStringList parses the body of a JS array of string literals
+func parseStringList(list string) []string {
+	var values []string
+	for _, m := range stringPattern.FindAllStringSubmatch(list, -1) {
+		if value := strings.TrimSpace(m[1]); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,94 @@
+package twitter_utils_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/twitter_utils"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseBundleURLs(t *testing.T) {
+	urls := twitter_utils.ParseBundleURLs(readFixture(t, "index.html"), "https://x.com/home")
+
+	want := []string{
+		"https://abs.twimg.com/responsive-web/client-web/api.5e6f7a8b.js",
+		"https://abs.twimg.com/responsive-web/client-web/main.9c0d1e2f.js",
+		"https://x.com/responsive-web/client-web/main.9c0d1e2f.js",
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("ParseBundleURLs() = %q, want %q", urls, want)
+	}
+}
+
+func TestParseBundleURLsWithoutBundles(t *testing.T) {
+	if urls := twitter_utils.ParseBundleURLs(`<script src="/sw.js"></script>`, "https://x.com/"); len(urls) != 0 {
+		t.Errorf("ParseBundleURLs() = %q, want none", urls)
+	}
+}
+
+func TestParseGraphQLOperations(t *testing.T) {
+	ops := twitter_utils.ParseGraphQLOperations(readFixture(t, "main.js"))
+
+	want := map[string]twitter_utils.GraphQLOperation{
+		"FavoriteTweet": {
+			QueryID:       "lI07N6Otwv1PhnEgXILM7A",
+			OperationName: "FavoriteTweet",
+			OperationType: "mutation",
+		},
+		"UnfavoriteTweet": {
+			QueryID:       "ZYKSe-w7KEslx3JhSIk5LA",
+			OperationName: "UnfavoriteTweet",
+			OperationType: "mutation",
+		},
+		"UserByScreenName": {
+			QueryID:       "xmU6X_CKVnQ5lSrCbAmJsg",
+			OperationName: "UserByScreenName",
+			OperationType: "query",
+			FeatureSwitches: []string{
+				"hidden_profile_subscriptions_enabled",
+				"rweb_tipjar_consumption_enabled",
+				"responsive_web_graphql_exclude_directive_enabled",
+				"rweb_profile_badges_enabled",
+			},
+			FieldToggles: []string{"withAuxiliaryUserLabels", "withProfileBadges"},
+		},
+		"Bookmarks": {
+			QueryID:         "QUjXply7fA7fk05FRyajEg",
+			OperationName:   "Bookmarks",
+			OperationType:   "query",
+			FeatureSwitches: []string{"graphql_timeline_v2_bookmark_timeline"},
+			FieldToggles:    []string{"withArticlePlainText", "withPayments"},
+		},
+		// Declared twice, the later declaration wins
+		"HomeTimeline": {
+			QueryID:         "HJFjzBgCs16TqxewQOeLNg",
+			OperationName:   "HomeTimeline",
+			OperationType:   "query",
+			FeatureSwitches: []string{"rweb_video_screen_enabled"},
+		},
+	}
+	if len(ops) != len(want) {
+		t.Errorf("ParseGraphQLOperations() returned %d operations, want %d", len(ops), len(want))
+	}
+	for name, wantOp := range want {
+		if got := ops[name]; !reflect.DeepEqual(got, wantOp) {
+			t.Errorf("ops[%q] = %+v, want %+v", name, got, wantOp)
+		}
+	}
+}
+
+func TestParseGraphQLOperationsWithoutOperations(t *testing.T) {
+	if ops := twitter_utils.ParseGraphQLOperations(`e.exports={queryId:"x"}`); len(ops) != 0 {
+		t.Errorf("ParseGraphQLOperations() = %+v, want none", ops)
+	}
+}
@@ -0,0 +1,9 @@
+(self.webpackChunk_twitter_responsive_web=self.webpackChunk_twitter_responsive_web||[]).push([["main"],{
+12345:e=>{e.exports={queryId:"lI07N6Otwv1PhnEgXILM7A",operationName:"FavoriteTweet",operationType:"mutation",metadata:{featureSwitches:[],fieldToggles:[]}}},
+12346:e=>{e.exports={queryId:"ZYKSe-w7KEslx3JhSIk5LA",operationName:"UnfavoriteTweet",operationType:"mutation"}},
+12347:e=>{e.exports={queryId: "xmU6X_CKVnQ5lSrCbAmJsg", operationName: "UserByScreenName", operationType: "query", metadata: {featureSwitches: ["hidden_profile_subscriptions_enabled", "rweb_tipjar_consumption_enabled", "responsive_web_graphql_exclude_directive_enabled", "rweb_profile_badges_enabled"], fieldToggles: ["withAuxiliaryUserLabels", "withProfileBadges"]}}},
+12348:e=>{e.exports={queryId:"QUjXply7fA7fk05FRyajEg",operationName:"Bookmarks",operationType:"query",metadata:{featureSwitches:["graphql_timeline_v2_bookmark_timeline"],fieldToggles:["withArticlePlainText", "withPayments"]}}},
+12349:e=>{e.exports={queryId:"oldRemovedQueryId00000",operationName:"HomeTimeline",operationType:"query",metadata:{featureSwitches:[],fieldToggles:[]}}},
+12350:e=>{e.exports={queryId:"HJFjzBgCs16TqxewQOeLNg",operationName:"HomeTimeline",operationType:"query",metadata:{featureSwitches:["rweb_video_screen_enabled"],fieldToggles:[]}}},
+12351:(e,t,n)=>{"use strict";n.d(t,{Z:()=>r});const r={queryId:"not-an-operation"}}
+}]);
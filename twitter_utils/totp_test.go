@@ -0,0 +1,69 @@
+package twitter_utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/twitter_utils"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors,
+// "12345678901234567890" in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTPVectors(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1, truncated to 6 digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := twitter_utils.GenerateTOTP(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateTOTP(%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateTOTP(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateTOTPSecretFormat(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	for _, secret := range []string{
+		"gezdgnbvgy3tqojqgezdgnbvgy3tqojq",
+		"GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ",
+		"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ====",
+	} {
+		if got, err := twitter_utils.GenerateTOTP(secret, at); err != nil || got != "081804" {
+			t.Errorf("GenerateTOTP(%q) = %q, %v, want 081804", secret, got, err)
+		}
+	}
+}
+
+func TestGenerateTOTPInvalidSecret(t *testing.T) {
+	for _, secret := range []string{"", "   ", "not base32!", "GEZDGNB1"} {
+		if code, err := twitter_utils.GenerateTOTP(secret, time.Now()); err == nil {
+			t.Errorf("GenerateTOTP(%q) = %q, want an error", secret, code)
+		}
+	}
+}
+
+func TestGenerateTOTPStep(t *testing.T) {
+	start, _ := twitter_utils.GenerateTOTP(rfc6238Secret, time.Unix(60, 0))
+	end, _ := twitter_utils.GenerateTOTP(rfc6238Secret, time.Unix(89, 0))
+	next, _ := twitter_utils.GenerateTOTP(rfc6238Secret, time.Unix(90, 0))
+	if start != end {
+		t.Errorf("codes within one 30 second step differ: %s, %s", start, end)
+	}
+	if next == end {
+		t.Errorf("code did not change at the next step: %s", next)
+	}
+}
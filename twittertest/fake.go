@@ -0,0 +1,636 @@
+package twittertest
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/client/addons"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// Fake is an in-memory client.API signed in as one account. It never
+// touches the network and follows the same rules as Server: liking,
+// retweeting or posting the same tweet twice reports StatusAlreadyDone,
+// locked and suspended accounts are rejected, and unknown tweets or users
+// fail with models.ErrNotFound.
+//
+// Example:
+//
+//	fake := twittertest.NewFake("alice")
+//	tweet := fake.AddTweet(twittertest.Tweet{Author: "bob", Text: "hi"})
+//	runBot(fake) // accepts a client.API
+//	if got, _ := fake.StoredTweet(tweet.ID); len(got.LikedBy) != 1 {
+//	    t.Errorf("tweet not liked")
+//	}
+type Fake struct {
+	*store
+
+	screenName string
+	failures   map[string][]error // Injected errors per method name
+	loggedOut  bool               // Set by Logout, fails every later call
+}
+
+var _ client.API = (*Fake)(nil)
+
+// NewFake returns a Fake signed in as screenName, creating the account
+func NewFake(screenName string) *Fake {
+	f := &Fake{store: newStore(), screenName: screenName, failures: make(map[string][]error)}
+	f.AddUser(User{ScreenName: screenName})
+	return f
+}
+
+// As returns a Fake signed in as another account that shares this fake's
+// state, creating the account if needed
+func (f *Fake) As(screenName string) *Fake {
+	if _, ok := f.GetUser(screenName); !ok {
+		f.AddUser(User{ScreenName: screenName})
+	}
+	return &Fake{store: f.store, screenName: screenName, failures: f.failures}
+}
+
+// Fail makes the next call to method (e.g. "Like") fail with err.
+// Context variants share the queue of their plain method.
+func (f *Fake) Fail(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[method] = append(f.failures[method], err)
+}
+
+// begin locks the store and returns the signed-in user, or the error the
+// call must fail with. The caller must unlock f.mu.
+func (f *Fake) begin(ctx context.Context, method string) (*User, error) {
+	f.mu.Lock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if queue := f.failures[method]; len(queue) > 0 {
+		f.failures[method] = queue[1:]
+		return nil, queue[0]
+	}
+
+	u := f.state.user(f.screenName)
+	switch {
+	case u == nil, f.loggedOut:
+		return nil, models.ErrAuthFailed
+	case u.Suspended:
+		return nil, models.ErrSuspended
+	case u.Locked:
+		return nil, models.ErrAccountLocked
+	}
+	return u, nil
+}
+
+func failed(err error) *models.ActionResponse {
+	return &models.ActionResponse{
+		Success: false,
+		Error:   err,
+		Status:  models.StatusFromError(err),
+	}
+}
+
+func succeeded(status models.ActionStatus) *models.ActionResponse {
+	return &models.ActionResponse{Success: true, Status: status}
+}
+
+// tweetID accepts a tweet ID or URL and validates it like the real client
+func tweetID(idOrURL string) (string, error) {
+	idOrURL = strings.TrimSpace(idOrURL)
+	if strings.Contains(idOrURL, "twitter.com") || strings.Contains(idOrURL, "x.com") {
+		id, err := addons.ExtractTweetID(idOrURL, "", utils.NewLogger(utils.LogLevelNone))
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid tweet URL: %w", models.ErrInvalidInput, err)
+		}
+		idOrURL = id
+	}
+	if err := models.ValidateTweetID(idOrURL); err != nil {
+		return "", err
+	}
+	return idOrURL, nil
+}
+
+// Like likes a stored tweet as the signed-in account
+func (f *Fake) Like(tweetID string) *models.ActionResponse {
+	return f.LikeContext(context.Background(), tweetID)
+}
+
+func (f *Fake) LikeContext(ctx context.Context, idOrURL string) *models.ActionResponse {
+	u, err := f.begin(ctx, "Like")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+
+	tweet, err := f.tweet(idOrURL)
+	if err != nil {
+		return failed(err)
+	}
+	if contains(tweet.LikedBy, u.ScreenName) {
+		return succeeded(models.StatusAlreadyDone)
+	}
+	tweet.LikedBy = append(tweet.LikedBy, u.ScreenName)
+	return succeeded(models.StatusSuccess)
+}
+
+// Retweet retweets a stored tweet, adding the retweet to the author's tweets
+func (f *Fake) Retweet(tweetID string) *models.ActionResponse {
+	return f.RetweetContext(context.Background(), tweetID)
+}
+
+func (f *Fake) RetweetContext(ctx context.Context, idOrURL string) *models.ActionResponse {
+	u, err := f.begin(ctx, "Retweet")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+
+	source, err := f.tweet(idOrURL)
+	if err != nil {
+		return failed(err)
+	}
+	if contains(source.RetweetedBy, u.ScreenName) {
+		return succeeded(models.StatusAlreadyDone)
+	}
+	source.RetweetedBy = append(source.RetweetedBy, u.ScreenName)
+	retweet := &Tweet{
+		ID:        f.state.newID(),
+		Author:    u.ScreenName,
+		Text:      "RT @" + source.Author + ": " + source.Text,
+		RetweetOf: source.ID,
+	}
+	f.state.tweets[retweet.ID] = retweet
+	return succeeded(models.StatusSuccess)
+}
+
+// Tweet stores a new tweet, uploading opts.MediaBase64 if set
+func (f *Fake) Tweet(content string, opts *client.TweetOptions) *models.ActionResponse {
+	return f.TweetContext(context.Background(), content, opts)
+}
+
+func (f *Fake) TweetContext(ctx context.Context, content string, opts *client.TweetOptions) *models.ActionResponse {
+	u, err := f.begin(ctx, "Tweet")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+
+	var media string
+	if opts != nil {
+		media = opts.MediaBase64
+	}
+	return f.post(u, content, "", media)
+}
+
+// Comment stores a reply to a stored tweet
+func (f *Fake) Comment(content string, tweetID string, opts *client.CommentOptions) *models.ActionResponse {
+	return f.CommentContext(context.Background(), content, tweetID, opts)
+}
+
+func (f *Fake) CommentContext(ctx context.Context, content string, idOrURL string, opts *client.CommentOptions) *models.ActionResponse {
+	u, err := f.begin(ctx, "Comment")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+
+	parent, err := f.tweet(idOrURL)
+	if err != nil {
+		return failed(err)
+	}
+	var media string
+	if opts != nil {
+		media = opts.MediaBase64
+	}
+	return f.post(u, content, parent.ID, media)
+}
+
+// post stores a tweet or reply; posting the same text twice is a duplicate
+func (f *Fake) post(u *User, content, inReplyTo, mediaBase64 string) *models.ActionResponse {
+	for _, t := range f.state.tweets {
+		if strings.EqualFold(t.Author, u.ScreenName) && t.Text == content && t.RetweetOf == "" {
+			return succeeded(models.StatusAlreadyDone)
+		}
+	}
+
+	tweet := &Tweet{ID: f.state.newID(), Author: u.ScreenName, Text: content, InReplyTo: inReplyTo}
+	if mediaBase64 != "" {
+		media, err := f.upload(mediaBase64)
+		if err != nil {
+			return failed(fmt.Errorf("failed to upload media: %w", err))
+		}
+		tweet.MediaIDs = []string{media.ID}
+	}
+	f.state.tweets[tweet.ID] = tweet
+	return succeeded(models.StatusSuccess)
+}
+
+// Follow follows a user, or requests to follow a protected one
+func (f *Fake) Follow(username string) *models.ActionResponse {
+	return f.FollowContext(context.Background(), username)
+}
+
+func (f *Fake) FollowContext(ctx context.Context, username string) *models.ActionResponse {
+	u, err := f.begin(ctx, "Follow")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+
+	username, err = models.NormalizeUsername(username)
+	if err != nil {
+		return failed(err)
+	}
+	target := f.state.user(username)
+	if target == nil {
+		return failed(fmt.Errorf("user %s: %w", username, models.ErrNotFound))
+	}
+	if target.Protected && !f.state.isFollowing(u.ScreenName, target.ScreenName) {
+		if f.state.isRequested(u.ScreenName, target.ScreenName) {
+			return succeeded(models.StatusAlreadyDone)
+		}
+		addEdge(f.state.requested, u.ScreenName, target.ScreenName)
+		return succeeded(models.StatusSuccess)
+	}
+	f.state.follow(u.ScreenName, target.ScreenName)
+	return succeeded(models.StatusSuccess)
+}
+
+// Unfollow removes a follow or a pending follow request
+func (f *Fake) Unfollow(userIDOrUsername string) *models.ActionResponse {
+	return f.UnfollowContext(context.Background(), userIDOrUsername)
+}
+
+func (f *Fake) UnfollowContext(ctx context.Context, userIDOrUsername string) *models.ActionResponse {
+	u, err := f.begin(ctx, "Unfollow")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+
+	var target *User
+	if userIDOrUsername = strings.TrimSpace(userIDOrUsername); utils.IsNumeric(userIDOrUsername) {
+		if err := models.ValidateUserID(userIDOrUsername); err != nil {
+			return failed(err)
+		}
+		target = f.state.userByID(userIDOrUsername)
+	} else {
+		username, err := models.NormalizeUsername(userIDOrUsername)
+		if err != nil {
+			return failed(err)
+		}
+		target = f.state.user(username)
+	}
+	if target == nil {
+		return failed(fmt.Errorf("user %s: %w", userIDOrUsername, models.ErrNotFound))
+	}
+	delete(f.state.following[strings.ToLower(u.ScreenName)], strings.ToLower(target.ScreenName))
+	delete(f.state.requested[strings.ToLower(u.ScreenName)], strings.ToLower(target.ScreenName))
+	return succeeded(models.StatusSuccess)
+}
+
+// VotePoll votes for the 1-based choice answer in a stored poll
+func (f *Fake) VotePoll(tweetID string, answer string) *models.ActionResponse {
+	return f.VotePollContext(context.Background(), tweetID, answer)
+}
+
+func (f *Fake) VotePollContext(ctx context.Context, idOrURL string, answer string) *models.ActionResponse {
+	u, err := f.begin(ctx, "VotePoll")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+
+	answer = strings.TrimSpace(answer)
+	if err := models.ValidatePollChoice(answer); err != nil {
+		return failed(err)
+	}
+	tweet, err := f.tweet(idOrURL)
+	if err != nil {
+		return failed(err)
+	}
+	if tweet.Poll == nil {
+		return failed(fmt.Errorf("tweet %s has no poll: %w", tweet.ID, models.ErrNotFound))
+	}
+	choice, err := strconv.Atoi(answer)
+	if err != nil || choice < 1 || choice > len(tweet.Poll.Choices) {
+		return failed(fmt.Errorf("invalid poll choice %q: %w", answer, models.ErrUnknown))
+	}
+	if _, voted := tweet.Poll.Votes[strings.ToLower(u.ScreenName)]; voted {
+		return failed(fmt.Errorf("already voted in poll %s: %w", tweet.ID, models.ErrAlreadyDone))
+	}
+	tweet.Poll.Votes[strings.ToLower(u.ScreenName)] = choice
+	return succeeded(models.StatusSuccess)
+}
+
+// UploadMedia stores base64 media and returns its ID
+func (f *Fake) UploadMedia(mediaBase64 string) (string, error) {
+	return f.UploadMediaContext(context.Background(), mediaBase64)
+}
+
+func (f *Fake) UploadMediaContext(ctx context.Context, mediaBase64 string) (string, error) {
+	_, err := f.begin(ctx, "UploadMedia")
+	defer f.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	media, err := f.upload(mediaBase64)
+	if err != nil {
+		return "", err
+	}
+	return media.ID, nil
+}
+
+func (f *Fake) upload(mediaBase64 string) (*Media, error) {
+	data, err := base64.StdEncoding.DecodeString(mediaBase64)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid media data: %w", models.ErrUnknown)
+	}
+	media := &Media{ID: f.state.newID(), Data: data}
+	f.state.media[media.ID] = media
+	return media, nil
+}
+
+// IsValid reports the signed-in account's state
+func (f *Fake) IsValid() (*client.AccountInfo, *models.ActionResponse) {
+	return f.IsValidContext(context.Background())
+}
+
+func (f *Fake) IsValidContext(ctx context.Context) (*client.AccountInfo, *models.ActionResponse) {
+	u, err := f.begin(ctx, "IsValid")
+	defer f.mu.Unlock()
+	if err != nil {
+		// The real client reports suspension as a successful check
+		if u := f.state.user(f.screenName); u != nil && u.Suspended && errors.Is(err, models.ErrSuspended) {
+			return &client.AccountInfo{Username: u.ScreenName, Suspended: true}, succeeded(models.StatusSuccess)
+		}
+		return nil, failed(err)
+	}
+
+	return &client.AccountInfo{
+		Name:      u.Name,
+		Username:  u.ScreenName,
+		Protected: u.Protected,
+		Verified:  u.Verified,
+	}, succeeded(models.StatusSuccess)
+}
+
+// GetUserInfoByUsername returns a stored user with counts computed from the state
+func (f *Fake) GetUserInfoByUsername(username string) (*client.UserInfoResponse, *models.ActionResponse) {
+	return f.GetUserInfoByUsernameContext(context.Background(), username)
+}
+
+func (f *Fake) GetUserInfoByUsernameContext(ctx context.Context, username string) (*client.UserInfoResponse, *models.ActionResponse) {
+	u, err := f.begin(ctx, "GetUserInfoByUsername")
+	defer f.mu.Unlock()
+	if err != nil {
+		return nil, failed(err)
+	}
+
+	username, err = models.NormalizeUsername(username)
+	if err != nil {
+		return nil, failed(err)
+	}
+	target := f.state.user(username)
+	if target == nil {
+		return nil, failed(fmt.Errorf("user %s: %w", username, models.ErrNotFound))
+	}
+
+	var info client.UserInfoResponse
+	result := &info.Data.User.Result
+	result.RestID = target.ID
+	result.IsBlueVerified = target.Verified
+	result.Legacy.Name = target.Name
+	result.Legacy.ScreenName = target.ScreenName
+	result.Legacy.Verified = target.Verified
+	result.Legacy.Following = f.state.isFollowing(u.ScreenName, target.ScreenName)
+	for follower, followed := range f.state.following {
+		if followed[strings.ToLower(target.ScreenName)] {
+			result.Legacy.FollowersCount++
+		}
+		if follower == strings.ToLower(target.ScreenName) {
+			result.Legacy.FriendsCount = len(followed)
+		}
+	}
+	for _, t := range f.state.tweets {
+		if strings.EqualFold(t.Author, target.ScreenName) {
+			result.Legacy.StatusesCount++
+		}
+	}
+	return &info, succeeded(models.StatusSuccess)
+}
+
+// GetTweet looks up a stored tweet like the real client does. Use
+// StoredTweet to inspect likes and retweets by screen name.
+func (f *Fake) GetTweet(tweetID string) (*client.TweetInfo, *models.ActionResponse) {
+	return f.GetTweetContext(context.Background(), tweetID)
+}
+
+func (f *Fake) GetTweetContext(ctx context.Context, tweetID string) (*client.TweetInfo, *models.ActionResponse) {
+	u, err := f.begin(ctx, "GetTweet")
+	defer f.mu.Unlock()
+	if err != nil {
+		return nil, failed(err)
+	}
+
+	tweet, err := f.tweet(tweetID)
+	if err != nil {
+		return nil, failed(err)
+	}
+
+	info := &client.TweetInfo{
+		ID:           tweet.ID,
+		Text:         tweet.Text,
+		InReplyToID:  tweet.InReplyTo,
+		LikeCount:    len(tweet.LikedBy),
+		RetweetCount: len(tweet.RetweetedBy),
+		Liked:        contains(tweet.LikedBy, u.ScreenName),
+		Retweeted:    contains(tweet.RetweetedBy, u.ScreenName),
+	}
+	if author := f.state.user(tweet.Author); author != nil {
+		info.AuthorID = author.ID
+		info.AuthorUsername = author.ScreenName
+		info.AuthorName = author.Name
+	}
+	for _, t := range f.state.tweets {
+		if t.InReplyTo == tweet.ID {
+			info.ReplyCount++
+		}
+	}
+	return info, succeeded(models.StatusSuccess)
+}
+
+// RateLimit reports no rate limits; the fake never limits requests
+func (f *Fake) RateLimit(endpoint string) (models.RateLimit, bool) {
+	return models.RateLimit{}, false
+}
+
+// RateLimits returns an empty map
+func (f *Fake) RateLimits() map[string]models.RateLimit {
+	return map[string]models.RateLimit{}
+}
+
+// ExportSession encodes a session for the signed-in account. It can be
+// decoded with models.DecodeSession but not restored against X.
+func (f *Fake) ExportSession(opts *client.SessionOptions) ([]byte, error) {
+	u, err := f.begin(context.Background(), "ExportSession")
+	defer f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var key []byte
+	if opts != nil {
+		key = opts.Key
+	}
+	authToken := fakeAuthToken(u)
+	now := time.Now()
+	return models.EncodeSession(&models.Session{
+		Username:    u.ScreenName,
+		UserID:      u.ID,
+		AuthToken:   authToken,
+		CreatedAt:   now,
+		ValidatedAt: now,
+		ExportedAt:  now,
+	}, key)
+}
+
+// Logout signs the fake out: later calls fail with models.ErrAuthFailed.
+// Logging out twice reports StatusAlreadyDone, like the real client.
+func (f *Fake) Logout(ctx context.Context) *models.ActionResponse {
+	u, err := f.begin(ctx, "Logout")
+	defer f.mu.Unlock()
+	if errors.Is(err, models.ErrAuthFailed) && f.loggedOut {
+		return succeeded(models.StatusAlreadyDone)
+	}
+	if err != nil {
+		return failed(err)
+	}
+
+	endSession(u, u.AuthToken)
+	f.loggedOut = true
+	return succeeded(models.StatusSuccess)
+}
+
+// Sessions lists the fake's own login followed by the user's Sessions
+func (f *Fake) Sessions(ctx context.Context) ([]client.ActiveSession, *models.ActionResponse) {
+	u, err := f.begin(ctx, "Sessions")
+	defer f.mu.Unlock()
+	if err != nil {
+		return nil, failed(err)
+	}
+	return sessionList(f.sessionTokens(u), fakeAuthToken(u)), succeeded(models.StatusSuccess)
+}
+
+// RevokeSession ends one of the logins listed by Sessions. Revoking the
+// fake's own login signs it out like Logout.
+func (f *Fake) RevokeSession(ctx context.Context, sessionID string) *models.ActionResponse {
+	u, err := f.begin(ctx, "RevokeSession")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+	return f.revokeSession(u, sessionID)
+}
+
+// RevokeOtherSessions ends every login listed by Sessions but the fake's own
+func (f *Fake) RevokeOtherSessions(ctx context.Context) (int, *models.ActionResponse) {
+	u, err := f.begin(ctx, "RevokeOtherSessions")
+	defer f.mu.Unlock()
+	if err != nil {
+		return 0, failed(err)
+	}
+
+	revoked := 0
+	for _, session := range sessionList(f.sessionTokens(u), fakeAuthToken(u)) {
+		if session.Current {
+			continue
+		}
+		if resp := f.revokeSession(u, session.ID); !resp.Success {
+			return revoked, resp
+		}
+		revoked++
+	}
+	return revoked, succeeded(models.StatusSuccess)
+}
+
+func (f *Fake) revokeSession(u *User, id string) *models.ActionResponse {
+	current := fakeAuthToken(u)
+	for _, token := range f.sessionTokens(u) {
+		if sessionID(token) != id {
+			continue
+		}
+		endSession(u, token)
+		if token == current {
+			f.loggedOut = true
+		}
+		return succeeded(models.StatusSuccess)
+	}
+	return failed(fmt.Errorf("session %s: %w", id, models.ErrNotFound))
+}
+
+// sessionTokens returns the logins of u, the fake's own one first even
+// if u has no AuthToken
+func (f *Fake) sessionTokens(u *User) []string {
+	tokens := sessionTokens(u)
+	if u.AuthToken == "" {
+		tokens = append([]string{fakeAuthToken(u)}, tokens...)
+	}
+	return tokens
+}
+
+// fakeAuthToken is the auth token the fake is signed in with
+func fakeAuthToken(u *User) string {
+	if u.AuthToken != "" {
+		return u.AuthToken
+	}
+	return "fake-" + u.ID
+}
+
+// GraphQL fails with errors.ErrUnsupported: the fake only simulates the
+// endpoints that have a dedicated method. Injected failures are returned first.
+func (f *Fake) GraphQL(ctx context.Context, operation string, variables any, features map[string]bool, out any) *models.ActionResponse {
+	_, err := f.begin(ctx, "GraphQL")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+	return failed(fmt.Errorf("twittertest: GraphQL operation %s: %w", operation, errors.ErrUnsupported))
+}
+
+// REST fails with errors.ErrUnsupported, see GraphQL
+func (f *Fake) REST(ctx context.Context, method, path string, form url.Values, out any) *models.ActionResponse {
+	_, err := f.begin(ctx, "REST")
+	defer f.mu.Unlock()
+	if err != nil {
+		return failed(err)
+	}
+	return failed(fmt.Errorf("twittertest: %s %s: %w", method, path, errors.ErrUnsupported))
+}
+
+// StoredTweet returns a copy of a stored tweet, like Server.GetTweet.
+// The GetTweet method of Fake is the client.API lookup instead.
+func (f *Fake) StoredTweet(id string) (Tweet, bool) {
+	return f.store.GetTweet(id)
+}
+
+// tweet resolves a tweet ID or URL to a stored tweet
+func (f *Fake) tweet(idOrURL string) (*Tweet, error) {
+	id, err := tweetID(idOrURL)
+	if err != nil {
+		return nil, err
+	}
+	t := f.state.tweets[id]
+	if t == nil {
+		return nil, fmt.Errorf("tweet %s: %w", id, models.ErrNotFound)
+	}
+	return t, nil
+}
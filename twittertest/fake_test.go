@@ -0,0 +1,152 @@
+package twittertest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+)
+
+// Both stand-ins must keep up with the client surface
+var (
+	_ client.API = (*client.Twitter)(nil)
+	_ client.API = (*twittertest.Fake)(nil)
+)
+
+func TestFakeLike(t *testing.T) {
+	fake := twittertest.NewFake("alice")
+	tweet := fake.AddTweet(twittertest.Tweet{Author: "bob", Text: "hi"})
+
+	var api client.API = fake
+	if resp := api.Like(tweet.ID); !resp.Success || resp.Status != models.StatusSuccess {
+		t.Fatalf("Like = %+v", resp)
+	}
+	if resp := api.Like("https://x.com/bob/status/" + tweet.ID); resp.Status != models.StatusAlreadyDone {
+		t.Errorf("Like by URL = %+v, want StatusAlreadyDone", resp)
+	}
+	if info, _ := fake.GetTweet(tweet.ID); info.LikeCount != 1 || !info.Liked {
+		t.Errorf("GetTweet = %+v, want one like by alice", info)
+	}
+	if got, _ := fake.StoredTweet(tweet.ID); len(got.LikedBy) != 1 || got.LikedBy[0] != "alice" {
+		t.Errorf("LikedBy = %v, want [alice]", got.LikedBy)
+	}
+}
+
+func TestFakeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(f *twittertest.Fake)
+		call   func(api client.API) *models.ActionResponse
+		err    error
+		status models.ActionStatus
+	}{
+		{
+			name:   "unknown tweet",
+			call:   func(api client.API) *models.ActionResponse { return api.Like("1") },
+			err:    models.ErrNotFound,
+			status: models.StatusNotFound,
+		},
+		{
+			name:   "invalid tweet ID",
+			call:   func(api client.API) *models.ActionResponse { return api.Retweet("12a") },
+			err:    models.ErrInvalidInput,
+			status: models.StatusUnknown,
+		},
+		{
+			name:   "unknown user",
+			call:   func(api client.API) *models.ActionResponse { return api.Follow("nobody") },
+			err:    models.ErrNotFound,
+			status: models.StatusNotFound,
+		},
+		{
+			name:   "locked account",
+			setup:  func(f *twittertest.Fake) { f.UpdateUser("alice", func(u *twittertest.User) { u.Locked = true }) },
+			call:   func(api client.API) *models.ActionResponse { return api.Tweet("hello", nil) },
+			err:    models.ErrAccountLocked,
+			status: models.StatusLocked,
+		},
+		{
+			name:  "injected failure",
+			setup: func(f *twittertest.Fake) { f.Fail("Tweet", models.ErrRateLimited) },
+			call: func(api client.API) *models.ActionResponse {
+				return api.TweetContext(context.Background(), "hello", nil)
+			},
+			err:    models.ErrRateLimited,
+			status: models.StatusRateLimited,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := twittertest.NewFake("alice")
+			if tt.setup != nil {
+				tt.setup(fake)
+			}
+			resp := tt.call(fake)
+			if resp.Success || !errors.Is(resp.Error, tt.err) || resp.Status != tt.status {
+				t.Errorf("got %+v, want %v with status %v", resp, tt.err, tt.status)
+			}
+		})
+	}
+}
+
+func TestFakeInjectedFailureIsConsumed(t *testing.T) {
+	fake := twittertest.NewFake("alice")
+	fake.Fail("Tweet", models.ErrRateLimited)
+
+	if resp := fake.Tweet("hello", nil); resp.Success {
+		t.Fatal("first Tweet succeeded despite the injected failure")
+	}
+	if resp := fake.Tweet("hello", nil); !resp.Success {
+		t.Errorf("second Tweet = %+v", resp)
+	}
+	if tweets := fake.TweetsBy("alice"); len(tweets) != 1 {
+		t.Errorf("tweets = %d, want 1", len(tweets))
+	}
+}
+
+func TestFakeAsSharesState(t *testing.T) {
+	alice := twittertest.NewFake("alice")
+	bob := alice.As("bob")
+	bob.UpdateUser("bob", func(u *twittertest.User) { u.Protected = true })
+
+	if resp := alice.Follow("bob"); !resp.Success {
+		t.Fatalf("Follow = %+v", resp)
+	}
+	if !alice.FollowRequested("alice", "bob") || alice.IsFollowing("alice", "bob") {
+		t.Error("following a protected account should leave a pending request")
+	}
+
+	tweet := bob.AddTweet(twittertest.Tweet{Author: "bob", Text: "mine"})
+	info, resp := alice.GetTweet(tweet.ID)
+	if !resp.Success || info.AuthorUsername != "bob" {
+		t.Errorf("GetTweet = %+v, %+v", info, resp)
+	}
+}
+
+func TestFakeLogout(t *testing.T) {
+	ctx := context.Background()
+	fake := twittertest.NewFake("alice")
+	fake.UpdateUser("alice", func(u *twittertest.User) { u.Sessions = []string{"phone-session-token"} })
+
+	sessions, resp := fake.Sessions(ctx)
+	if !resp.Success || len(sessions) != 2 || !sessions[0].Current {
+		t.Fatalf("Sessions = %+v, %+v", sessions, resp)
+	}
+	if n, resp := fake.RevokeOtherSessions(ctx); n != 1 || !resp.Success {
+		t.Errorf("RevokeOtherSessions = %d, %+v", n, resp)
+	}
+
+	if resp := fake.Logout(ctx); resp.Status != models.StatusSuccess {
+		t.Errorf("Logout = %+v", resp)
+	}
+	if resp := fake.Logout(ctx); resp.Status != models.StatusAlreadyDone {
+		t.Errorf("second Logout = %+v, want StatusAlreadyDone", resp)
+	}
+	if resp := fake.Tweet("hello", nil); resp.Status != models.StatusAuthError {
+		t.Errorf("Tweet after Logout = %+v, want StatusAuthError", resp)
+	}
+}
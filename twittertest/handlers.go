@@ -0,0 +1,351 @@
+package twittertest
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GraphQL errors come back with HTTP 200 and an errors array, like X does
+func graphQLError(code int, message string) response {
+	return response{status: http.StatusOK, body: errorBody(code, message)}
+}
+
+// REST errors use a 4xx status
+func restError(status, code int, message string) response {
+	return response{status: status, body: errorBody(code, message)}
+}
+
+func ok(body any) response {
+	return response{status: http.StatusOK, body: body}
+}
+
+func stringVar(r *request, name string) string {
+	v, _ := r.variables[name].(string)
+	return v
+}
+
+func legacyUser(u *User) map[string]any {
+	return map[string]any{
+		"id":               mustAtoi(u.ID),
+		"id_str":           u.ID,
+		"name":             u.Name,
+		"screen_name":      u.ScreenName,
+		"protected":        u.Protected,
+		"verified":         u.Verified,
+		"followers_count":  0,
+		"friends_count":    0,
+		"statuses_count":   0,
+		"favourites_count": 0,
+	}
+}
+
+func mustAtoi(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+func (s *Server) viewer(r *request) response {
+	return ok(map[string]any{
+		"data": map[string]any{
+			"viewer": map[string]any{
+				"user_results": map[string]any{
+					"result": map[string]any{
+						"rest_id": r.user.ID,
+						"legacy":  legacyUser(r.user),
+					},
+				},
+			},
+		},
+	})
+}
+
+func (s *Server) userByScreenName(r *request) response {
+	u := s.state.user(stringVar(r, "screen_name"))
+	if u == nil {
+		// X answers unknown screen names with an empty data object
+		return ok(map[string]any{"data": map[string]any{}})
+	}
+
+	legacy := legacyUser(u)
+	legacy["following"] = s.state.isFollowing(r.screenName(), u.ScreenName)
+	return ok(map[string]any{
+		"data": map[string]any{
+			"user": map[string]any{
+				"result": map[string]any{
+					"rest_id":          u.ID,
+					"legacy":           legacy,
+					"is_blue_verified": u.Verified,
+				},
+			},
+		},
+	})
+}
+
+func (s *Server) favoriteTweet(r *request) response {
+	tweet := s.state.tweets[stringVar(r, "tweet_id")]
+	if tweet == nil {
+		return graphQLError(144, "No status found with that ID.")
+	}
+	if contains(tweet.LikedBy, r.user.ScreenName) {
+		return graphQLError(139, "You have already favorited this status.")
+	}
+
+	tweet.LikedBy = append(tweet.LikedBy, r.user.ScreenName)
+	return ok(map[string]any{"data": map[string]any{"favorite_tweet": "Done"}})
+}
+
+func (s *Server) createRetweet(r *request) response {
+	source := s.state.tweets[stringVar(r, "tweet_id")]
+	if source == nil {
+		return graphQLError(144, "No status found with that ID.")
+	}
+	if contains(source.RetweetedBy, r.user.ScreenName) {
+		return graphQLError(327, "You have already retweeted this Tweet.")
+	}
+
+	source.RetweetedBy = append(source.RetweetedBy, r.user.ScreenName)
+	retweet := &Tweet{
+		ID:        s.state.newID(),
+		Author:    r.user.ScreenName,
+		Text:      "RT @" + source.Author + ": " + source.Text,
+		RetweetOf: source.ID,
+	}
+	s.state.tweets[retweet.ID] = retweet
+
+	return ok(map[string]any{
+		"data": map[string]any{
+			"create_retweet": map[string]any{
+				"retweet_results": map[string]any{
+					"result": map[string]any{
+						"rest_id": retweet.ID,
+						"legacy":  map[string]any{"full_text": retweet.Text},
+					},
+				},
+			},
+		},
+	})
+}
+
+func (s *Server) createTweet(r *request) response {
+	text := stringVar(r, "tweet_text")
+	for _, t := range s.state.tweets {
+		if strings.EqualFold(t.Author, r.user.ScreenName) && t.Text == text && t.RetweetOf == "" {
+			return graphQLError(187, "Status is a duplicate.")
+		}
+	}
+
+	tweet := &Tweet{
+		ID:     s.state.newID(),
+		Author: r.user.ScreenName,
+		Text:   text,
+	}
+	if reply, ok := r.variables["reply"].(map[string]any); ok {
+		tweet.InReplyTo, _ = reply["in_reply_to_tweet_id"].(string)
+		if s.state.tweets[tweet.InReplyTo] == nil {
+			return graphQLError(144, "No status found with that ID.")
+		}
+	}
+	if media, ok := r.variables["media"].(map[string]any); ok {
+		entities, _ := media["media_entities"].([]any)
+		for _, entity := range entities {
+			if e, ok := entity.(map[string]any); ok {
+				id, _ := e["media_id"].(string)
+				if s.state.media[id] == nil {
+					return graphQLError(324, "Invalid media id.")
+				}
+				tweet.MediaIDs = append(tweet.MediaIDs, id)
+			}
+		}
+	}
+	s.state.tweets[tweet.ID] = tweet
+
+	return ok(map[string]any{
+		"data": map[string]any{
+			"create_tweet": map[string]any{
+				"tweet_results": map[string]any{
+					"result": map[string]any{
+						"rest_id": tweet.ID,
+						"legacy":  map[string]any{"full_text": tweet.Text},
+					},
+				},
+			},
+		},
+	})
+}
+
+func (s *Server) tweetDetail(r *request) response {
+	tweet := s.state.tweets[stringVar(r, "focalTweetId")]
+	if tweet == nil {
+		return graphQLError(144, "No status found with that ID.")
+	}
+
+	result := map[string]any{
+		"__typename": "Tweet",
+		"rest_id":    tweet.ID,
+		"legacy": map[string]any{
+			"full_text":      tweet.Text,
+			"favorite_count": len(tweet.LikedBy),
+			"retweet_count":  len(tweet.RetweetedBy),
+			"favorited":      contains(tweet.LikedBy, r.screenName()),
+			"retweeted":      contains(tweet.RetweetedBy, r.screenName()),
+		},
+	}
+	if u := s.state.user(tweet.Author); u != nil {
+		result["core"] = map[string]any{
+			"user_results": map[string]any{
+				"result": map[string]any{"rest_id": u.ID, "legacy": legacyUser(u)},
+			},
+		}
+	}
+	if tweet.Poll != nil {
+		result["card"] = pollCard(tweet.Poll)
+	}
+
+	return ok(map[string]any{
+		"data": map[string]any{
+			"threaded_conversation_with_injections_v2": map[string]any{
+				"instructions": []any{
+					map[string]any{
+						"type": "TimelineAddEntries",
+						"entries": []any{
+							map[string]any{
+								"entryId": "tweet-" + tweet.ID,
+								"content": map[string]any{
+									"itemContent": map[string]any{
+										"tweet_results": map[string]any{"result": result},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// pollName is the card name X uses for text polls with n choices
+func pollName(poll *Poll) string {
+	return "poll" + strconv.Itoa(len(poll.Choices)) + "choice_text_only"
+}
+
+func pollCard(poll *Poll) map[string]any {
+	counts := make([]int, len(poll.Choices))
+	for _, choice := range poll.Votes {
+		if choice >= 1 && choice <= len(counts) {
+			counts[choice-1]++
+		}
+	}
+
+	var values []any
+	for i, label := range poll.Choices {
+		n := strconv.Itoa(i + 1)
+		values = append(values,
+			map[string]any{"key": "choice" + n + "_label", "value": map[string]any{"string_value": label, "type": "STRING"}},
+			map[string]any{"key": "choice" + n + "_count", "value": map[string]any{"string_value": strconv.Itoa(counts[i]), "type": "STRING"}},
+		)
+	}
+
+	return map[string]any{
+		"rest_id": "card://" + poll.CardID,
+		"legacy": map[string]any{
+			"binding_values": values,
+			"name":           pollName(poll),
+			"url":            "card://" + poll.CardID,
+		},
+	}
+}
+
+func (s *Server) friendshipsCreate(r *request) response {
+	target := s.state.user(r.form.Get("screen_name"))
+	if target == nil {
+		target = s.state.userByID(r.form.Get("user_id"))
+	}
+	if target == nil {
+		return restError(http.StatusNotFound, 50, "User not found.")
+	}
+
+	body := legacyUser(target)
+	if target.Protected && !s.state.isFollowing(r.user.ScreenName, target.ScreenName) {
+		if s.state.isRequested(r.user.ScreenName, target.ScreenName) {
+			return restError(http.StatusForbidden, 160, "You've already requested to follow "+target.ScreenName+".")
+		}
+		addEdge(s.state.requested, r.user.ScreenName, target.ScreenName)
+		body["follow_request_sent"] = true
+		return ok(body)
+	}
+
+	s.state.follow(r.user.ScreenName, target.ScreenName)
+	body["following"] = true
+	return ok(body)
+}
+
+func (s *Server) friendshipsDestroy(r *request) response {
+	target := s.state.userByID(r.form.Get("user_id"))
+	if target == nil {
+		target = s.state.user(r.form.Get("screen_name"))
+	}
+	if target == nil {
+		return restError(http.StatusNotFound, 50, "User not found.")
+	}
+
+	delete(s.state.following[strings.ToLower(r.user.ScreenName)], strings.ToLower(target.ScreenName))
+	delete(s.state.requested[strings.ToLower(r.user.ScreenName)], strings.ToLower(target.ScreenName))
+	body := legacyUser(target)
+	body["following"] = false
+	return ok(body)
+}
+
+func (s *Server) accountMultiList(r *request) response {
+	return ok(map[string]any{
+		"users": []any{
+			map[string]any{
+				"user_id":          r.user.ID,
+				"name":             r.user.Name,
+				"screen_name":      r.user.ScreenName,
+				"avatar_image_url": "",
+				"is_suspended":     r.user.Suspended,
+				"is_verified":      r.user.Verified,
+				"is_protected":     r.user.Protected,
+				"is_auth_valid":    true,
+			},
+		},
+	})
+}
+
+func (s *Server) mediaUpload(r *request) response {
+	data, err := base64.StdEncoding.DecodeString(r.form.Get("media_data"))
+	if err != nil || len(data) == 0 {
+		return restError(http.StatusBadRequest, 38, "media parameter is missing or invalid.")
+	}
+
+	media := &Media{ID: s.state.newID(), Data: data}
+	s.state.media[media.ID] = media
+	return ok(map[string]any{
+		"media_id":           mustAtoi(media.ID),
+		"media_id_string":    media.ID,
+		"size":               len(data),
+		"expires_after_secs": 86400,
+	})
+}
+
+func (s *Server) capsPassthrough(r *request) response {
+	tweet := s.state.tweets[r.form.Get("twitter:long:original_tweet_id")]
+	if tweet == nil || tweet.Poll == nil ||
+		r.form.Get("twitter:string:card_uri") != "card://"+tweet.Poll.CardID {
+		return restError(http.StatusNotFound, 34, "Sorry, that page does not exist.")
+	}
+
+	choice, err := strconv.Atoi(r.form.Get("twitter:string:selected_choice"))
+	if err != nil || choice < 1 || choice > len(tweet.Poll.Choices) {
+		return restError(http.StatusBadRequest, 214, "Invalid poll choice.")
+	}
+	if _, voted := tweet.Poll.Votes[strings.ToLower(r.user.ScreenName)]; voted {
+		return restError(http.StatusForbidden, 139, "You have already voted in this poll.")
+	}
+
+	tweet.Poll.Votes[strings.ToLower(r.user.ScreenName)] = choice
+	return ok(map[string]any{"card": pollCard(tweet.Poll)})
+}
@@ -0,0 +1,192 @@
+package twittertest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/twitter_utils"
+)
+
+// loginFlow is a login in progress on the onboarding API
+type loginFlow struct {
+	user *User  // Set once the identifier step matched an account
+	next string // Subtask the flow waits for
+}
+
+// Subtasks the server's login flow asks for, in order. The two-factor
+// and email steps are only asked for users that enable them.
+var loginSubtasks = map[string]map[string]any{
+	"LoginJsInstrumentationSubtask": {"subtask_id": "LoginJsInstrumentationSubtask", "js_instrumentation": map[string]any{"url": "https://twitter.com/i/js_inst?c_name=ui_metrics"}},
+	"LoginEnterUserIdentifierSSO":   {"subtask_id": "LoginEnterUserIdentifierSSO", "settings_list": map[string]any{}},
+	"LoginEnterPassword":            {"subtask_id": "LoginEnterPassword", "enter_password": map[string]any{}},
+	"LoginTwoFactorAuthChallenge": {"subtask_id": "LoginTwoFactorAuthChallenge", "enter_text": map[string]any{
+		"primary_text":  map[string]any{"text": "Enter your verification code"},
+		"keyboard_type": "text",
+	}},
+	"LoginAcid": {"subtask_id": "LoginAcid", "enter_text": map[string]any{
+		"primary_text":   map[string]any{"text": "Enter your phone number or email address"},
+		"secondary_text": map[string]any{"text": "There was unusual login activity on your account."},
+		"keyboard_type":  "email",
+	}},
+	"AccountDuplicationCheck": {"subtask_id": "AccountDuplicationCheck", "check_logged_in_account": map[string]any{}},
+	"LoginSuccessSubtask":     {"subtask_id": "LoginSuccessSubtask"},
+}
+
+func (s *Server) guestActivate(r *request) response {
+	token := randomHex(10)
+	s.guestTokens[token] = true
+	return ok(map[string]any{"guest_token": token})
+}
+
+// onboardingTask runs the login flow: every request answers the subtask
+// the flow waits for, and the response names the next one
+func (s *Server) onboardingTask(r *request) response {
+	if r.URL.Query().Get("flow_name") == "login" {
+		token := "g;" + randomHex(16)
+		s.flows[token] = &loginFlow{next: "LoginJsInstrumentationSubtask"}
+		return flowResponse(token, "LoginJsInstrumentationSubtask")
+	}
+
+	var in struct {
+		FlowToken     string                       `json:"flow_token"`
+		SubtaskInputs []map[string]json.RawMessage `json:"subtask_inputs"`
+	}
+	json.Unmarshal(r.body, &in)
+
+	flow := s.flows[in.FlowToken]
+	if flow == nil {
+		return restError(http.StatusBadRequest, 366, "flow_token is invalid.")
+	}
+	if len(in.SubtaskInputs) == 0 {
+		return restError(http.StatusBadRequest, 366, "Missing subtask input.")
+	}
+	input := in.SubtaskInputs[0]
+	var id string
+	json.Unmarshal(input["subtask_id"], &id)
+	if id != flow.next {
+		return restError(http.StatusBadRequest, 366, "Unexpected subtask "+id+".")
+	}
+
+	switch id {
+	case "LoginJsInstrumentationSubtask":
+		flow.next = "LoginEnterUserIdentifierSSO"
+
+	case "LoginEnterUserIdentifierSSO":
+		var settings struct {
+			SettingResponses []struct {
+				Key          string `json:"key"`
+				ResponseData struct {
+					TextData struct {
+						Result string `json:"result"`
+					} `json:"text_data"`
+				} `json:"response_data"`
+			} `json:"setting_responses"`
+		}
+		json.Unmarshal(input["settings_list"], &settings)
+		identifier := ""
+		for _, setting := range settings.SettingResponses {
+			if setting.Key == "user_identifier" {
+				identifier = setting.ResponseData.TextData.Result
+			}
+		}
+		flow.user = s.state.loginUser(identifier)
+		if flow.user == nil {
+			return restError(http.StatusBadRequest, 399, "Sorry, we could not find your account.")
+		}
+		flow.next = "LoginEnterPassword"
+
+	case "LoginEnterPassword":
+		var password struct {
+			Password string `json:"password"`
+		}
+		json.Unmarshal(input["enter_password"], &password)
+		if password.Password != flow.user.Password {
+			return restError(http.StatusBadRequest, 399, "Wrong password!")
+		}
+		flow.next = afterPassword(flow.user, "LoginEnterPassword")
+
+	case "LoginTwoFactorAuthChallenge":
+		if !validTOTP(flow.user.TOTPSecret, enterText(input)) {
+			return restError(http.StatusBadRequest, 399, "The code you entered is incorrect. Please try again.")
+		}
+		flow.next = afterPassword(flow.user, id)
+
+	case "LoginAcid":
+		if !strings.EqualFold(enterText(input), flow.user.Email) {
+			return restError(http.StatusBadRequest, 399, "Incorrect. Please try again.")
+		}
+		flow.next = afterPassword(flow.user, id)
+
+	case "AccountDuplicationCheck":
+		delete(s.flows, in.FlowToken)
+		if flow.user.AuthToken == "" {
+			flow.user.AuthToken = randomHex(20)
+		}
+		resp := flowResponse(in.FlowToken, "LoginSuccessSubtask")
+		resp.body.(map[string]any)["status"] = "success"
+		resp.cookies = []*http.Cookie{
+			{Name: "auth_token", Value: flow.user.AuthToken, Path: "/", HttpOnly: true},
+			{Name: "ct0", Value: randomHex(16), Path: "/"},
+		}
+		return resp
+	}
+
+	return flowResponse(in.FlowToken, flow.next)
+}
+
+// afterPassword returns the step that follows done for u
+func afterPassword(u *User, done string) string {
+	steps := []string{"LoginEnterPassword"}
+	if u.TOTPSecret != "" {
+		steps = append(steps, "LoginTwoFactorAuthChallenge")
+	}
+	if u.ConfirmEmail {
+		steps = append(steps, "LoginAcid")
+	}
+	steps = append(steps, "AccountDuplicationCheck")
+
+	for i, step := range steps[:len(steps)-1] {
+		if step == done {
+			return steps[i+1]
+		}
+	}
+	return "AccountDuplicationCheck"
+}
+
+func flowResponse(token, subtask string) response {
+	return ok(map[string]any{
+		"flow_token": token,
+		"status":     "in_progress",
+		"subtasks":   []map[string]any{loginSubtasks[subtask]},
+	})
+}
+
+// enterText reads the answer of an enter_text subtask
+func enterText(input map[string]json.RawMessage) string {
+	var text struct {
+		Text string `json:"text"`
+	}
+	json.Unmarshal(input["enter_text"], &text)
+	return strings.TrimSpace(text.Text)
+}
+
+// validTOTP accepts the current code and the previous one, for clock skew
+func validTOTP(secret, code string) bool {
+	now := time.Now()
+	for _, at := range []time.Time{now, now.Add(-30 * time.Second)} {
+		if expected, err := twitter_utils.GenerateTOTP(secret, at); err == nil && code == expected {
+			return true
+		}
+	}
+	return false
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,509 @@
+// Package twittertest provides an in-process fake of the X endpoints used
+// by the client package, for testing code built on top of it without
+// network access.
+//
+// The Server keeps users, tweets, follows, media and polls in memory,
+// checks auth_token and CSRF cookies like X does, and can be scripted to
+// fail: inject error responses per operation, lock or suspend accounts,
+// or enforce rate limits. Users with a Password can sign in with
+// client.Login through the onboarding flow, and profiles can be read
+// without an account through client.NewGuestTwitter.
+//
+// Example:
+//
+//	srv := twittertest.NewServer()
+//	defer srv.Close()
+//	srv.AddUser(twittertest.User{ScreenName: "alice", AuthToken: "token"})
+//	tweet := srv.AddTweet(twittertest.Tweet{Author: "alice", Text: "hello"})
+//
+//	twitter, err := srv.NewTwitter("alice")
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	twitter.Like(tweet.ID)
+//
+//	if got, _ := srv.GetTweet(tweet.ID); len(got.LikedBy) != 1 {
+//	    t.Errorf("tweet not liked")
+//	}
+package twittertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// Operation names of the REST endpoints, as reported by utils.OperationFromURL.
+// GraphQL endpoints use their operation name, e.g. "FavoriteTweet".
+const (
+	OpFriendshipsCreate  = models.PathFriendshipsCreate
+	OpFriendshipsDestroy = models.PathFriendshipsDestroy
+	OpAccountMultiList   = models.PathAccountMultiList
+	OpMediaUpload        = models.PathMediaUpload
+	OpCapsPassthrough    = models.PathCapsPassthrough
+	OpGuestActivate      = models.PathGuestActivate
+	OpOnboardingTask     = models.PathOnboardingTask
+	OpAccountLogout      = models.PathAccountLogout
+	OpSessionsList       = models.PathSessionsList
+	OpSessionsRevoke     = models.PathSessionsRevoke
+)
+
+// guestOperations authenticate with a guest token instead of a session
+var guestOperations = map[string]bool{
+	OpGuestActivate:  true,
+	OpOnboardingTask: true,
+}
+
+// guestReadOperations accept a guest token in place of a session, like
+// the operations marked Guest in models.DefaultOperations
+var guestReadOperations = map[string]bool{
+	"UserByScreenName": true,
+}
+
+// Server is a fake X server. It serves every host of models.Hosts from a
+// single httptest.Server; use Config to point a client at it.
+type Server struct {
+	*httptest.Server
+	*store
+
+	faults     map[string][]*Fault
+	limits     map[string]rateLimit
+	usage      map[string]*rateUsage // Per operation and account
+	requests   []Request
+	handlers   map[string]handlerFunc
+	rotateCt0  bool
+	issuedCt0s int
+
+	guestTokens map[string]bool
+	flows       map[string]*loginFlow // Login flows in progress by flow token
+}
+
+// Request is a request received by the server
+type Request struct {
+	Method     string
+	Operation  string
+	ScreenName string // Authenticated account, empty if none
+	CSRFToken  string // x-csrf-token header, empty if none
+	Variables  map[string]any
+	Form       url.Values
+}
+
+// Fault is a scripted error response
+type Fault struct {
+	StatusCode int
+	Code       int // X error code, 0 to send no errors array
+	Message    string
+	Header     http.Header
+
+	// Times is the number of requests the fault applies to,
+	// 0 means every request until ClearFaults
+	Times int
+}
+
+type rateLimit struct {
+	limit  int
+	window time.Duration
+}
+
+type rateUsage struct {
+	used  int
+	reset time.Time
+}
+
+type handlerFunc func(s *Server, r *request) response
+
+// NewServer starts a fake X server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		store:       newStore(),
+		faults:      make(map[string][]*Fault),
+		limits:      make(map[string]rateLimit),
+		usage:       make(map[string]*rateUsage),
+		guestTokens: make(map[string]bool),
+		flows:       make(map[string]*loginFlow),
+	}
+	s.handlers = map[string]handlerFunc{
+		"Viewer":             (*Server).viewer,
+		"UserByScreenName":   (*Server).userByScreenName,
+		"FavoriteTweet":      (*Server).favoriteTweet,
+		"CreateRetweet":      (*Server).createRetweet,
+		"CreateTweet":        (*Server).createTweet,
+		"TweetDetail":        (*Server).tweetDetail,
+		OpFriendshipsCreate:  (*Server).friendshipsCreate,
+		OpFriendshipsDestroy: (*Server).friendshipsDestroy,
+		OpAccountMultiList:   (*Server).accountMultiList,
+		OpMediaUpload:        (*Server).mediaUpload,
+		OpCapsPassthrough:    (*Server).capsPassthrough,
+		OpGuestActivate:      (*Server).guestActivate,
+		OpOnboardingTask:     (*Server).onboardingTask,
+		OpAccountLogout:      (*Server).accountLogout,
+		OpSessionsList:       (*Server).sessionsList,
+		OpSessionsRevoke:     (*Server).sessionsRevoke,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Config returns a client configuration that sends every request to the
+// server, with short retry delays and logging disabled
+func (s *Server) Config() *models.Config {
+	config := models.NewConfig()
+	config.Hosts = models.Hosts{Web: s.URL, API: s.URL, Upload: s.URL, Caps: s.URL}
+	config.HttpClient = utils.NewStdHttpClient(s.Client())
+	config.LogLevel = utils.LogLevelNone
+	config.Retry.BaseDelay = time.Millisecond
+	config.Retry.MaxDelay = 10 * time.Millisecond
+	config.RateLimiter.Backoff = 10 * time.Millisecond
+	return config
+}
+
+// NewTwitter creates a client signed in as screenName with Config
+func (s *Server) NewTwitter(screenName string) (*client.Twitter, error) {
+	u, ok := s.GetUser(screenName)
+	if !ok || u.AuthToken == "" {
+		return nil, fmt.Errorf("twittertest: no account %q with an auth token", screenName)
+	}
+	return client.NewTwitter(client.NewAccount(u.AuthToken, "", ""), s.Config())
+}
+
+// NewClient is NewTwitter for tests: it adds screenName with a random auth
+// token unless the account exists, applies configure (if any) to Config
+// and fails t if the client cannot be created.
+//
+// Example:
+//
+//	srv := twittertest.NewServer()
+//	t.Cleanup(srv.Close)
+//	twitter := srv.NewClient(t, "alice", func(c *models.Config) { c.SessionRecovery.Enabled = false })
+func (s *Server) NewClient(t testing.TB, screenName string, configure func(*models.Config)) *client.Twitter {
+	t.Helper()
+	u, ok := s.GetUser(screenName)
+	if !ok {
+		u = s.AddUser(User{ScreenName: screenName, AuthToken: randomHex(20)})
+	} else if u.AuthToken == "" {
+		s.UpdateUser(screenName, func(stored *User) { stored.AuthToken = randomHex(20) })
+		u, _ = s.GetUser(screenName)
+	}
+
+	config := s.Config()
+	if configure != nil {
+		configure(config)
+	}
+	twitter, err := client.NewTwitter(client.NewAccount(u.AuthToken, "", ""), config)
+	if err != nil {
+		t.Fatalf("twittertest: failed to create a client for %s: %v", screenName, err)
+	}
+	return twitter
+}
+
+// Inject makes requests to operation fail with f. Use "*" to match every
+// operation. Faults for the same operation apply in the order injected.
+func (s *Server) Inject(operation string, f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[operation] = append(s.faults[operation], &f)
+}
+
+// ClearFaults removes every injected fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = make(map[string][]*Fault)
+}
+
+// SetRateLimit limits each account to limit requests to operation per
+// window and sends x-rate-limit-* headers on its responses.
+// A limit of 0 removes the limit.
+func (s *Server) SetRateLimit(operation string, limit int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit <= 0 {
+		delete(s.limits, operation)
+		return
+	}
+	s.limits[operation] = rateLimit{limit: limit, window: window}
+}
+
+// RotateCt0 makes the server issue a new ct0 cookie on every response,
+// like X does after some requests
+func (s *Server) RotateCt0(rotate bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotateCt0 = rotate
+}
+
+// ExpireGuestTokens makes the server reject every guest token issued so
+// far with code 239, as X does when they expire
+func (s *Server) ExpireGuestTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guestTokens = make(map[string]bool)
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Calls returns how many requests operation received
+func (s *Server) Calls(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, r := range s.requests {
+		if r.Operation == operation {
+			n++
+		}
+	}
+	return n
+}
+
+// RateLimited returns a fault answering with HTTP 429 and code 88,
+// with rate limit headers resetting at reset
+func RateLimited(reset time.Time) Fault {
+	header := http.Header{}
+	header.Set("x-rate-limit-limit", "50")
+	header.Set("x-rate-limit-remaining", "0")
+	header.Set("x-rate-limit-reset", strconv.FormatInt(reset.Unix(), 10))
+	return Fault{StatusCode: http.StatusTooManyRequests, Code: 88, Message: "Rate limit exceeded.", Header: header}
+}
+
+// ServerError returns a fault answering with the given 5xx status and no body errors
+func ServerError(statusCode int) Fault {
+	return Fault{StatusCode: statusCode, Message: http.StatusText(statusCode)}
+}
+
+// request is a parsed incoming request
+type request struct {
+	*http.Request
+	operation string
+	user      *User
+	authToken string
+	variables map[string]any
+	form      url.Values
+	body      []byte
+}
+
+// screenName returns the authenticated account, or "" for guests
+func (r *request) screenName() string {
+	if r.user == nil {
+		return ""
+	}
+	return r.user.ScreenName
+}
+
+// response is what a handler answers with
+type response struct {
+	status  int
+	body    any
+	cookies []*http.Cookie
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, hr *http.Request) {
+	r := &request{Request: hr, operation: utils.OperationFromURL(hr.URL.String())}
+	body, _ := io.ReadAll(hr.Body)
+	r.variables, r.form = parseInput(hr, body)
+	r.body = body
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	authToken, ct0 := cookieValue(hr, "auth_token"), cookieValue(hr, "ct0")
+	r.user, r.authToken = s.state.userByToken(authToken), authToken
+
+	logged := Request{
+		Method:    hr.Method,
+		Operation: r.operation,
+		CSRFToken: hr.Header.Get("x-csrf-token"),
+		Variables: r.variables,
+		Form:      r.form,
+	}
+	if r.user != nil {
+		logged.ScreenName = r.user.ScreenName
+	}
+	s.requests = append(s.requests, logged)
+
+	handler, ok := s.handlers[r.operation]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorBody(34, "Sorry, that page does not exist."))
+		return
+	}
+
+	if f := s.nextFault(r.operation); f != nil {
+		for key, values := range f.Header {
+			w.Header()[key] = values
+		}
+		if f.Code == 0 {
+			writeJSON(w, f.StatusCode, map[string]any{"message": f.Message})
+			return
+		}
+		writeJSON(w, f.StatusCode, errorBody(f.Code, f.Message))
+		return
+	}
+
+	// Endpoints used before login need a guest token, once activated
+	if guestOperations[r.operation] {
+		if r.operation != OpGuestActivate && !s.guestTokens[hr.Header.Get("x-guest-token")] {
+			writeJSON(w, http.StatusForbidden, errorBody(239, "Bad guest token."))
+			return
+		}
+		resp := handler(s, r)
+		for _, cookie := range resp.cookies {
+			http.SetCookie(w, cookie)
+		}
+		writeJSON(w, resp.status, resp.body)
+		return
+	}
+
+	// Authentication, in the order X checks it
+	guest := r.user == nil && guestReadOperations[r.operation] && hr.Header.Get("x-guest-token") != ""
+	switch {
+	case guest:
+		if !s.guestTokens[hr.Header.Get("x-guest-token")] {
+			writeJSON(w, http.StatusForbidden, errorBody(239, "Bad guest token."))
+			return
+		}
+	case r.user == nil:
+		writeJSON(w, http.StatusUnauthorized, errorBody(32, "Could not authenticate you."))
+		return
+	case ct0 == "" || hr.Header.Get("x-csrf-token") != ct0:
+		writeJSON(w, http.StatusForbidden, errorBody(353, "This request requires a matching csrf cookie and header."))
+		return
+	case r.user.Suspended:
+		writeJSON(w, http.StatusForbidden, errorBody(64, "Your account is suspended and is not permitted to access this feature."))
+		return
+	case r.user.Locked:
+		writeJSON(w, http.StatusForbidden, errorBody(326, "To protect our users from spam and other malicious activity, this account is temporarily locked."))
+		return
+	}
+
+	if !s.takeRateLimit(w, r) {
+		writeJSON(w, http.StatusTooManyRequests, errorBody(88, "Rate limit exceeded."))
+		return
+	}
+
+	if !guest {
+		if s.rotateCt0 {
+			s.issuedCt0s++
+			ct0 = fmt.Sprintf("%032x", time.Now().UnixNano()+int64(s.issuedCt0s))
+		}
+		http.SetCookie(w, &http.Cookie{Name: "ct0", Value: ct0, Path: "/"})
+	}
+
+	resp := handler(s, r)
+	for _, cookie := range resp.cookies {
+		http.SetCookie(w, cookie)
+	}
+	writeJSON(w, resp.status, resp.body)
+}
+
+// nextFault returns the fault to apply to operation, if any
+func (s *Server) nextFault(operation string) *Fault {
+	for _, key := range []string{operation, "*"} {
+		queue := s.faults[key]
+		if len(queue) == 0 {
+			continue
+		}
+		f := queue[0]
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults[key] = queue[1:]
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// takeRateLimit counts the request against its rate limit and sets the
+// rate limit headers. It reports false if the limit is exhausted.
+func (s *Server) takeRateLimit(w http.ResponseWriter, r *request) bool {
+	limit, ok := s.limits[r.operation]
+	if !ok {
+		return true
+	}
+
+	key := r.operation + " " + strings.ToLower(r.screenName())
+	usage := s.usage[key]
+	now := time.Now()
+	if usage == nil || !now.Before(usage.reset) {
+		usage = &rateUsage{reset: now.Add(limit.window)}
+		s.usage[key] = usage
+	}
+
+	allowed := usage.used < limit.limit
+	if allowed {
+		usage.used++
+	}
+
+	w.Header().Set("x-rate-limit-limit", strconv.Itoa(limit.limit))
+	w.Header().Set("x-rate-limit-remaining", strconv.Itoa(limit.limit-usage.used))
+	w.Header().Set("x-rate-limit-reset", strconv.FormatInt(usage.reset.Unix(), 10))
+	return allowed
+}
+
+// parseInput extracts GraphQL variables from the query or JSON body, and
+// form fields from urlencoded bodies
+func parseInput(r *http.Request, body []byte) (map[string]any, url.Values) {
+	variables := map[string]any{}
+	if raw := r.URL.Query().Get("variables"); raw != "" {
+		json.Unmarshal([]byte(raw), &variables)
+	}
+
+	contentType := r.Header.Get("content-type")
+	switch {
+	case strings.Contains(contentType, "application/json") && len(body) > 0:
+		var payload struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.Unmarshal(body, &payload); err == nil && payload.Variables != nil {
+			variables = payload.Variables
+		}
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		form, _ := url.ParseQuery(string(body))
+		return variables, form
+	}
+	return variables, url.Values{}
+}
+
+// cookieValue reads a cookie from the raw Cookie header the client sends
+func cookieValue(r *http.Request, name string) string {
+	for _, part := range strings.Split(r.Header.Get("cookie"), ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && key == name {
+			return value
+		}
+	}
+	return ""
+}
+
+func errorBody(code int, message string) map[string]any {
+	return map[string]any{
+		"errors": []map[string]any{{"code": code, "message": message}},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(body)
+	w.Header().Set("content-type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,141 @@
+package twittertest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+	"github.com/Tootoohk/TwitterAPI/models"
+	"github.com/Tootoohk/TwitterAPI/twittertest"
+)
+
+// newServer starts a server with alice signed in and a tweet by bob
+func newServer(t *testing.T) (*twittertest.Server, *client.Twitter, twittertest.Tweet) {
+	t.Helper()
+	srv := twittertest.NewServer()
+	t.Cleanup(srv.Close)
+	twitter := srv.NewClient(t, "alice", nil)
+	srv.AddUser(twittertest.User{ScreenName: "bob"})
+	tweet := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "hello"})
+	return srv, twitter, tweet
+}
+
+func TestServerLike(t *testing.T) {
+	srv, twitter, tweet := newServer(t)
+
+	if resp := twitter.Like(tweet.ID); !resp.Success || resp.Status != models.StatusSuccess {
+		t.Fatalf("Like = %+v", resp)
+	}
+	if got, _ := srv.GetTweet(tweet.ID); len(got.LikedBy) != 1 || got.LikedBy[0] != "alice" {
+		t.Errorf("LikedBy = %v, want [alice]", got.LikedBy)
+	}
+	if resp := twitter.Like(tweet.ID); !resp.Success || resp.Status != models.StatusAlreadyDone {
+		t.Errorf("second Like = %+v, want StatusAlreadyDone", resp)
+	}
+	if n := srv.Calls("FavoriteTweet"); n != 2 {
+		t.Errorf("FavoriteTweet calls = %d, want 2", n)
+	}
+}
+
+func TestServerLockedAccount(t *testing.T) {
+	srv, twitter, tweet := newServer(t)
+	srv.UpdateUser("alice", func(u *twittertest.User) { u.Locked = true })
+
+	resp := twitter.Like(tweet.ID)
+	if resp.Success || resp.Status != models.StatusLocked || !errors.Is(resp.Error, models.ErrAccountLocked) {
+		t.Errorf("Like = %+v, want StatusLocked", resp)
+	}
+	if got, _ := srv.GetTweet(tweet.ID); len(got.LikedBy) != 0 {
+		t.Errorf("locked account liked the tweet: %v", got.LikedBy)
+	}
+}
+
+func TestServerSuspendedAtStart(t *testing.T) {
+	srv := twittertest.NewServer()
+	defer srv.Close()
+	srv.AddUser(twittertest.User{ScreenName: "alice", AuthToken: "alice-auth-token", Suspended: true})
+
+	if _, err := srv.NewTwitter("alice"); err == nil {
+		t.Error("NewTwitter succeeded for a suspended account")
+	}
+}
+
+func TestServerRateLimit(t *testing.T) {
+	srv, _, tweet := newServer(t)
+	srv.SetRateLimit("FavoriteTweet", 1, time.Minute)
+	second := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "again"})
+
+	// Without the client-side limiter the second request reaches the server
+	twitter := srv.NewClient(t, "alice", func(c *models.Config) { c.RateLimiter.Enabled = false })
+
+	resp := twitter.Like(tweet.ID)
+	if !resp.Success {
+		t.Fatalf("first Like = %+v", resp)
+	}
+	if resp.RateLimit == nil || resp.RateLimit.Limit != 1 || resp.RateLimit.Remaining != 0 {
+		t.Errorf("RateLimit = %+v, want limit 1 with none remaining", resp.RateLimit)
+	}
+
+	resp = twitter.Like(second.ID)
+	if resp.Success || resp.Status != models.StatusRateLimited {
+		t.Errorf("second Like = %+v, want StatusRateLimited", resp)
+	}
+	if got, _ := srv.GetTweet(second.ID); len(got.LikedBy) != 0 {
+		t.Errorf("rate limited request liked the tweet: %v", got.LikedBy)
+	}
+}
+
+func TestServerRateLimitClientSide(t *testing.T) {
+	srv, _, tweet := newServer(t)
+	srv.SetRateLimit("FavoriteTweet", 1, time.Minute)
+	second := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "again"})
+
+	twitter := srv.NewClient(t, "alice", func(c *models.Config) { c.RateLimiter.MaxWait = time.Millisecond })
+
+	if resp := twitter.Like(tweet.ID); !resp.Success {
+		t.Fatalf("first Like = %+v", resp)
+	}
+	resp := twitter.Like(second.ID)
+	if resp.Success || !errors.Is(resp.Error, models.ErrRateLimited) {
+		t.Errorf("second Like = %+v, want ErrRateLimited", resp)
+	}
+	if n := srv.Calls("FavoriteTweet"); n != 1 {
+		t.Errorf("FavoriteTweet calls = %d, want the limiter to hold back the second", n)
+	}
+}
+
+func TestServerRotatedCt0(t *testing.T) {
+	srv, twitter, _ := newServer(t)
+	srv.RotateCt0(true)
+
+	seen := map[string]bool{twitter.Account.Ct0: true}
+	for i := 0; i < 3; i++ {
+		tweet := srv.AddTweet(twittertest.Tweet{Author: "bob", Text: "rotate"})
+		// The server rejects a ct0 header that does not match the cookie,
+		// so every success shows the rotated value was sent
+		if resp := twitter.Like(tweet.ID); !resp.Success {
+			t.Fatalf("Like %d = %+v", i, resp)
+		}
+		ct0 := twitter.Account.Ct0
+		if seen[ct0] {
+			t.Errorf("Like %d: ct0 %q was not rotated", i, ct0)
+		}
+		seen[ct0] = true
+		if jar, _ := twitter.Cookies.Value(twitter.Config.WebURL("/"), "ct0"); jar != ct0 {
+			t.Errorf("Like %d: Account.Ct0 = %q, jar has %q", i, ct0, jar)
+		}
+	}
+}
+
+func TestServerInjectedCsrfFault(t *testing.T) {
+	srv, twitter, tweet := newServer(t)
+	srv.Inject("FavoriteTweet", twittertest.Fault{StatusCode: 403, Code: 353, Message: "csrf", Times: 1})
+
+	if resp := twitter.Like(tweet.ID); !resp.Success {
+		t.Fatalf("Like = %+v, want the session refreshed and the request retried", resp)
+	}
+	if n := srv.Calls("FavoriteTweet"); n != 2 {
+		t.Errorf("FavoriteTweet calls = %d, want 2", n)
+	}
+}
@@ -0,0 +1,87 @@
+package twittertest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/Tootoohk/TwitterAPI/client"
+)
+
+// sessionTokens returns the auth tokens u is signed in with, AuthToken first
+func sessionTokens(u *User) []string {
+	tokens := make([]string, 0, len(u.Sessions)+1)
+	if u.AuthToken != "" {
+		tokens = append(tokens, u.AuthToken)
+	}
+	return append(tokens, u.Sessions...)
+}
+
+// sessionID is the hashed token the sessions endpoints identify a login by
+func sessionID(authToken string) string {
+	sum := sha256.Sum256([]byte(authToken))
+	return hex.EncodeToString(sum[:16])
+}
+
+// sessionList describes the logins using tokens, marking the one signed
+// in with current
+func sessionList(tokens []string, current string) []client.ActiveSession {
+	var sessions []client.ActiveSession
+	for _, token := range tokens {
+		sessions = append(sessions, client.ActiveSession{
+			ID:          sessionID(token),
+			Current:     token == current,
+			Application: "Twitter Web App",
+		})
+	}
+	return sessions
+}
+
+// endSession signs out the login of u that uses authToken
+func endSession(u *User, authToken string) {
+	if authToken == "" {
+		return
+	}
+	if u.AuthToken == authToken {
+		u.AuthToken = ""
+	}
+	for i, token := range u.Sessions {
+		if token == authToken {
+			u.Sessions = append(u.Sessions[:i:i], u.Sessions[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *Server) accountLogout(r *request) response {
+	endSession(r.user, r.authToken)
+	resp := ok(map[string]any{"status": "ok"})
+	resp.cookies = []*http.Cookie{
+		{Name: "auth_token", Path: "/", MaxAge: -1},
+		{Name: "ct0", Path: "/", MaxAge: -1},
+	}
+	return resp
+}
+
+func (s *Server) sessionsList(r *request) response {
+	sessions := []map[string]any{}
+	for _, session := range sessionList(sessionTokens(r.user), r.authToken) {
+		sessions = append(sessions, map[string]any{
+			"hashed_token":            session.ID,
+			"is_current":              session.Current,
+			"client_application_name": session.Application,
+		})
+	}
+	return ok(map[string]any{"sessions": sessions})
+}
+
+func (s *Server) sessionsRevoke(r *request) response {
+	id := r.form.Get("hashed_token")
+	for _, token := range sessionTokens(r.user) {
+		if sessionID(token) == id {
+			endSession(r.user, token)
+			return ok(map[string]any{})
+		}
+	}
+	return restError(http.StatusNotFound, 34, "Sorry, that page does not exist.")
+}
@@ -0,0 +1,314 @@
+package twittertest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// User is an account known to the fake server. Users with an AuthToken
+// can sign in; the others can only be looked up, followed and mentioned.
+type User struct {
+	ID         string
+	ScreenName string
+	Name       string
+	AuthToken  string
+
+	Protected bool
+	Verified  bool
+	Suspended bool // Every authenticated request fails with code 64
+	Locked    bool // Every authenticated request fails with code 326
+
+	// Login flow, see client.Login. Users without a Password cannot log in.
+	Password     string
+	Email        string // Accepted as the user identifier, and by ConfirmEmail
+	TOTPSecret   string // Enables the two-factor step
+	ConfirmEmail bool   // Ask to confirm Email after the password (LoginAcid)
+
+	// Sessions holds the auth tokens of other logins, listed next to
+	// AuthToken by the sessions endpoint. Each of them signs in as the user.
+	Sessions []string
+}
+
+// Tweet is a tweet stored by the fake server
+type Tweet struct {
+	ID          string
+	Author      string // Screen name
+	Text        string
+	InReplyTo   string
+	RetweetOf   string
+	MediaIDs    []string
+	LikedBy     []string
+	RetweetedBy []string
+	Poll        *Poll
+}
+
+// Poll is a text-only poll card attached to a tweet
+type Poll struct {
+	CardID  string
+	Choices []string
+	Votes   map[string]int // Lowercase screen name to 1-based choice
+}
+
+// Media is an uploaded media item
+type Media struct {
+	ID   string
+	Data []byte
+}
+
+// state is the fake server's in-memory store. Every method expects the
+// server mutex to be held.
+type state struct {
+	nextID    int64
+	users     map[string]*User // Lowercase screen name to user
+	tweets    map[string]*Tweet
+	following map[string]map[string]bool // Follower to followed screen names
+	requested map[string]map[string]bool // Pending requests to protected users
+	media     map[string]*Media
+}
+
+// store guards a state and exposes it to tests. It is shared by Server
+// and Fake, so both simulate X the same way.
+type store struct {
+	mu    sync.Mutex
+	state *state
+}
+
+func newStore() *store {
+	return &store{state: newState()}
+}
+
+func newState() *state {
+	return &state{
+		nextID:    1800000000000000000,
+		users:     make(map[string]*User),
+		tweets:    make(map[string]*Tweet),
+		following: make(map[string]map[string]bool),
+		requested: make(map[string]map[string]bool),
+		media:     make(map[string]*Media),
+	}
+}
+
+func (s *state) newID() string {
+	s.nextID++
+	return strconv.FormatInt(s.nextID, 10)
+}
+
+func (s *state) user(screenName string) *User {
+	return s.users[strings.ToLower(screenName)]
+}
+
+func (s *state) userByID(id string) *User {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *state) userByToken(authToken string) *User {
+	if authToken == "" {
+		return nil
+	}
+	for _, u := range s.users {
+		for _, token := range sessionTokens(u) {
+			if token == authToken {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+// loginUser finds the account a login identifier (screen name or email)
+// belongs to, if it can log in
+func (s *state) loginUser(identifier string) *User {
+	identifier = strings.TrimPrefix(strings.TrimSpace(identifier), "@")
+	for _, u := range s.users {
+		if u.Password == "" {
+			continue
+		}
+		if strings.EqualFold(u.ScreenName, identifier) || (u.Email != "" && strings.EqualFold(u.Email, identifier)) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *state) follow(follower, followed string) {
+	addEdge(s.following, follower, followed)
+}
+
+func (s *state) isFollowing(follower, followed string) bool {
+	return s.following[strings.ToLower(follower)][strings.ToLower(followed)]
+}
+
+func (s *state) isRequested(follower, followed string) bool {
+	return s.requested[strings.ToLower(follower)][strings.ToLower(followed)]
+}
+
+func addEdge(edges map[string]map[string]bool, from, to string) {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	if edges[from] == nil {
+		edges[from] = make(map[string]bool)
+	}
+	edges[from][to] = true
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func remove(list []string, value string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if !strings.EqualFold(v, value) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// copy returns a deep copy safe to hand out to tests
+func (t *Tweet) copy() Tweet {
+	c := *t
+	c.MediaIDs = append([]string(nil), t.MediaIDs...)
+	c.LikedBy = append([]string(nil), t.LikedBy...)
+	c.RetweetedBy = append([]string(nil), t.RetweetedBy...)
+	if t.Poll != nil {
+		poll := *t.Poll
+		poll.Choices = append([]string(nil), t.Poll.Choices...)
+		poll.Votes = make(map[string]int, len(t.Poll.Votes))
+		for k, v := range t.Poll.Votes {
+			poll.Votes[k] = v
+		}
+		c.Poll = &poll
+	}
+	return c
+}
+
+// AddUser stores u and returns it with generated fields filled in.
+// An existing user with the same screen name is replaced.
+func (s *store) AddUser(u User) User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u.ID == "" {
+		u.ID = s.state.newID()
+	}
+	if u.Name == "" {
+		u.Name = u.ScreenName
+	}
+	s.state.users[strings.ToLower(u.ScreenName)] = &u
+	return u
+}
+
+// UpdateUser changes a stored user, e.g. to lock or suspend it mid-test.
+// It reports whether the user exists.
+func (s *store) UpdateUser(screenName string, update func(u *User)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.state.user(screenName)
+	if u == nil {
+		return false
+	}
+	update(u)
+	return true
+}
+
+// GetUser returns a copy of a stored user
+func (s *store) GetUser(screenName string) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.state.user(screenName)
+	if u == nil {
+		return User{}, false
+	}
+	return *u, true
+}
+
+// AddTweet stores t and returns it with its ID filled in. Set Poll.Choices
+// to attach a poll; the card ID is generated.
+func (s *store) AddTweet(t Tweet) Tweet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.ID == "" {
+		t.ID = s.state.newID()
+	}
+	if t.Poll != nil {
+		if t.Poll.CardID == "" {
+			t.Poll.CardID = s.state.newID()
+		}
+		if t.Poll.Votes == nil {
+			t.Poll.Votes = make(map[string]int)
+		}
+	}
+	stored := t.copy()
+	s.state.tweets[t.ID] = &stored
+	return stored.copy()
+}
+
+// GetTweet returns a copy of a stored tweet
+func (s *store) GetTweet(id string) (Tweet, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.state.tweets[id]
+	if !ok {
+		return Tweet{}, false
+	}
+	return t.copy(), true
+}
+
+// TweetsBy returns copies of the tweets, replies and retweets posted by
+// screenName, oldest first
+func (s *store) TweetsBy(screenName string) []Tweet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tweets []Tweet
+	for _, t := range s.state.tweets {
+		if strings.EqualFold(t.Author, screenName) {
+			tweets = append(tweets, t.copy())
+		}
+	}
+	sort.Slice(tweets, func(i, j int) bool { return tweets[i].ID < tweets[j].ID })
+	return tweets
+}
+
+// IsFollowing reports whether follower follows followed
+func (s *store) IsFollowing(follower, followed string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.isFollowing(follower, followed)
+}
+
+// FollowRequested reports whether follower has a pending request to the
+// protected account followed
+func (s *store) FollowRequested(follower, followed string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.isRequested(follower, followed)
+}
+
+// GetMedia returns a copy of an uploaded media item
+func (s *store) GetMedia(id string) (Media, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.state.media[id]
+	if !ok {
+		return Media{}, false
+	}
+	return Media{ID: m.ID, Data: append([]byte(nil), m.Data...)}, true
+}
@@ -0,0 +1,368 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// ErrCassetteMiss is returned in replay mode for requests the cassette has no recording of
+var ErrCassetteMiss = errors.New("no recorded interaction matches the request")
+
+// CassetteMode selects whether a Cassette records or replays
+type CassetteMode int
+
+const (
+	CassetteReplay CassetteMode = iota // Serve recorded responses only, never touch the network
+	CassetteRecord                     // Send requests and append them to the cassette
+	CassetteAuto                       // Replay if the cassette file exists, record otherwise
+)
+
+// CassetteOptions configures NewCassette
+type CassetteOptions struct {
+	Path string
+	Mode CassetteMode
+
+	// Client sends requests while recording
+	Client HttpClient
+
+	// Redactor scrubs recorded requests and responses.
+	// Defaults to NewRedactor(), which knows the usual credential shapes.
+	Redactor *Redactor
+}
+
+// Interaction is one recorded request/response pair
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the part of a request used for matching and debugging
+type RecordedRequest struct {
+	Method    string              `json:"method"`
+	URL       string              `json:"url"`
+	Operation string              `json:"operation"`
+	Variables string              `json:"variables,omitempty"`
+	Headers   map[string][]string `json:"headers,omitempty"`
+	Body      string              `json:"body,omitempty"`
+}
+
+// RecordedResponse is a stored response
+type RecordedResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Headers    map[string][]string `json:"headers,omitempty"`
+	Body       string              `json:"body"`
+}
+
+// cassetteFile is the on-disk format
+type cassetteFile struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Cassette is an HttpClient that records request/response pairs to a JSON
+// file or replays them offline. Requests are matched on method, operation
+// and variables; repeated identical requests replay in recorded order.
+// Credentials are redacted before anything is kept. A recording cassette
+// writes its file once, on Close.
+//
+// Example:
+//
+//	cassette, err := utils.NewCassette(utils.CassetteOptions{
+//	    Path: "testdata/like.json",
+//	    Mode: utils.CassetteAuto,
+//	})
+//	defer cassette.Close()
+//	config := models.NewConfig()
+//	config.HttpClient = cassette
+type Cassette struct {
+	mu           sync.Mutex
+	path         string
+	mode         CassetteMode
+	client       HttpClient
+	redactor     *Redactor
+	interactions []Interaction
+	played       map[string]int // Replay position per match key
+	dirty        bool           // Recorded interactions not yet written
+}
+
+// NewCassette opens the cassette at opts.Path. Replay mode requires the file
+// to exist; record mode starts from an empty cassette and overwrites the
+// file on Close.
+func NewCassette(opts CassetteOptions) (*Cassette, error) {
+	if opts.Path == "" {
+		return nil, errors.New("cassette path is required")
+	}
+
+	mode := opts.Mode
+	if mode == CassetteAuto {
+		mode = CassetteRecord
+		if _, err := os.Stat(opts.Path); err == nil {
+			mode = CassetteReplay
+		}
+	}
+
+	c := &Cassette{
+		path:     opts.Path,
+		mode:     mode,
+		client:   opts.Client,
+		redactor: opts.Redactor,
+		played:   make(map[string]int),
+	}
+	if c.redactor == nil {
+		c.redactor = NewRedactor()
+	}
+
+	switch mode {
+	case CassetteReplay:
+		data, err := os.ReadFile(opts.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		var file cassetteFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", opts.Path, err)
+		}
+		c.interactions = file.Interactions
+	case CassetteRecord:
+		if c.client == nil {
+			client, err := NewHttpClient(HttpClientOptions{Timeout: 30 * time.Second, FollowRedirects: true})
+			if err != nil {
+				return nil, err
+			}
+			c.client = client
+		}
+	}
+
+	return c, nil
+}
+
+// Recording reports whether the cassette sends requests to the network
+func (c *Cassette) Recording() bool {
+	return c.mode == CassetteRecord
+}
+
+// Interactions returns a copy of the recorded interactions
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Do records or replays req
+func (c *Cassette) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	recorded := c.recordRequest(req, body)
+
+	if c.mode == CassetteReplay {
+		return c.replay(req, recorded)
+	}
+	return c.record(req, recorded)
+}
+
+func (c *Cassette) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := matchKey(recorded)
+	var matches []int
+	for i, interaction := range c.interactions {
+		if matchKey(interaction.Request) == key {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s %s variables=%s", ErrCassetteMiss, recorded.Method, recorded.Operation, recorded.Variables)
+	}
+
+	// Play matches in order, then keep repeating the last one
+	n := c.played[key]
+	if n >= len(matches) {
+		n = len(matches) - 1
+	}
+	c.played[key]++
+
+	stored := c.interactions[matches[n]].Response
+	header := make(http.Header, len(stored.Headers))
+	for k, v := range stored.Headers {
+		header[k] = append([]string(nil), v...)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", stored.StatusCode, http.StatusText(stored.StatusCode)),
+		StatusCode:    stored.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(stored.Body)),
+		ContentLength: int64(len(stored.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (c *Cassette) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    c.redactHeaders(resp.Header),
+			Body:       c.redactor.Redact(string(body)),
+		},
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, interaction)
+	c.dirty = true
+	return resp, nil
+}
+
+// Close writes the interactions recorded since the last Close to the
+// cassette file. It does nothing in replay mode.
+func (c *Cassette) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mode != CassetteRecord || !c.dirty {
+		return nil
+	}
+	if err := c.save(); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// save writes the cassette file. The caller must hold c.mu.
+func (c *Cassette) save() error {
+	data, err := json.MarshalIndent(cassetteFile{Interactions: c.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(c.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// recordRequest captures the redacted request and its match fields
+func (c *Cassette) recordRequest(req *http.Request, body []byte) RecordedRequest {
+	rawURL := req.URL.String()
+	recorded := RecordedRequest{
+		Method:    req.Method,
+		URL:       c.redactor.Redact(rawURL),
+		Operation: OperationFromURL(rawURL),
+		Variables: c.redactor.Redact(requestVariables(req, body)),
+		Headers:   c.redactHeaders(req.Header),
+	}
+	if utf8.Valid(body) {
+		recorded.Body = c.redactor.Redact(string(body))
+	}
+	return recorded
+}
+
+// redactHeaders copies header without ordering keys, transfer details and
+// credentials. Cookie values are scrubbed but names are kept, so replayed
+// Set-Cookie headers still provide cookies such as ct0.
+func (c *Cassette) redactHeaders(header http.Header) map[string][]string {
+	out := make(map[string][]string)
+	for key, values := range header {
+		switch strings.ToLower(key) {
+		case strings.ToLower(http.HeaderOrderKey), strings.ToLower(http.PHeaderOrderKey),
+			"content-encoding", "content-length", "transfer-encoding":
+			continue
+		case "authorization", "x-csrf-token", "cookie":
+			out[key] = []string{Redacted}
+			continue
+		}
+
+		redacted := make([]string, len(values))
+		for i, v := range values {
+			redacted[i] = c.redactor.Redact(v)
+		}
+		out[key] = redacted
+	}
+	return out
+}
+
+// requestVariables extracts what distinguishes two calls to the same
+// operation: the GraphQL variables from the query or JSON body, or the
+// form fields of REST calls. JSON is canonicalized so key order is ignored.
+func requestVariables(req *http.Request, body []byte) string {
+	if variables := req.URL.Query().Get("variables"); variables != "" {
+		return canonicalJSON([]byte(variables))
+	}
+
+	contentType := req.Header.Get("content-type")
+	switch {
+	case strings.Contains(contentType, "application/json"):
+		var payload map[string]json.RawMessage
+		if err := json.Unmarshal(body, &payload); err == nil {
+			if variables, ok := payload["variables"]; ok {
+				return canonicalJSON(variables)
+			}
+		}
+		return canonicalJSON(body)
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			return form.Encode()
+		}
+	}
+
+	// Fall back to the query string for everything else (e.g. media uploads)
+	return req.URL.Query().Encode()
+}
+
+// canonicalJSON re-encodes data with sorted keys, or returns it unchanged if it is not JSON
+func canonicalJSON(data []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return string(data)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return string(data)
+	}
+	return string(out)
+}
+
+func matchKey(r RecordedRequest) string {
+	return r.Method + " " + r.Operation + " " + r.Variables
+}
@@ -0,0 +1,116 @@
+package utils_test
+
+import (
+	"errors"
+	"io"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+const (
+	testAuthToken = "0123456789abcdef0123456789abcdef01234567"
+	testCt0       = "fedcba9876543210fedcba9876543210"
+	testBearer    = "Bearer AAAAAAAAAAAAAAAAAAAAAtestbearertoken"
+)
+
+func likeRequest(url string) utils.RequestConfig {
+	return utils.RequestConfig{
+		Method: "POST",
+		URL:    url + "/i/api/graphql/abc/FavoriteTweet",
+		Body:   strings.NewReader(`{"variables":{"tweet_id":"1"},"queryId":"abc"}`),
+		Headers: []utils.HeaderPair{
+			{Key: "authorization", Value: testBearer},
+			{Key: "content-type", Value: "application/json"},
+			{Key: "cookie", Value: "auth_token=" + testAuthToken + "; ct0=" + testCt0},
+			{Key: "x-csrf-token", Value: testCt0},
+		},
+	}
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		nethttp.SetCookie(w, &nethttp.Cookie{Name: "ct0", Value: testCt0, Path: "/"})
+		w.Header().Set("content-type", "application/json")
+		io.WriteString(w, `{"data":{"favorite_tweet":"Done"},"auth_token":"`+testAuthToken+`"}`)
+	}))
+
+	path := filepath.Join(t.TempDir(), "like.json")
+	recorder, err := utils.NewCassette(utils.CassetteOptions{
+		Path:   path,
+		Mode:   utils.CassetteRecord,
+		Client: utils.NewStdHttpClient(srv.Client()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, resp, err := utils.MakeRequest(recorder, likeRequest(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusOK || !strings.Contains(string(body), "Done") {
+		t.Fatalf("recorded response = %d %s", resp.StatusCode, body)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("cassette written before Close: %v", err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{testAuthToken, testCt0, "testbearertoken"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains credential %q:\n%s", secret, data)
+		}
+	}
+
+	player, err := utils.NewCassette(utils.CassetteOptions{Path: path, Mode: utils.CassetteAuto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Recording() {
+		t.Fatal("CassetteAuto recorded although the cassette exists")
+	}
+	body, resp, err = utils.MakeRequest(player, likeRequest(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusOK || !strings.Contains(string(body), `"favorite_tweet":"Done"`) {
+		t.Fatalf("replayed response = %d %s", resp.StatusCode, body)
+	}
+	if cookies := resp.Cookies(); len(cookies) != 1 || cookies[0].Name != "ct0" {
+		t.Errorf("replayed cookies = %v, want ct0", cookies)
+	}
+}
+
+func TestCassetteReplayFixture(t *testing.T) {
+	player, err := utils.NewCassette(utils.CassetteOptions{Path: "testdata/cassette.json", Mode: utils.CassetteReplay})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, resp, err := utils.MakeRequest(player, likeRequest("https://x.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusOK || !strings.Contains(string(body), "Done") {
+		t.Errorf("replayed response = %d %s", resp.StatusCode, body)
+	}
+
+	other := likeRequest("https://x.com")
+	other.Body = strings.NewReader(`{"variables":{"tweet_id":"2"},"queryId":"abc"}`)
+	if _, _, err := utils.MakeRequest(player, other); !errors.Is(err, utils.ErrCassetteMiss) {
+		t.Errorf("unrecorded request error = %v, want ErrCassetteMiss", err)
+	}
+}
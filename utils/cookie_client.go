@@ -1,20 +1,58 @@
 package utils
 
 import (
-	http "github.com/bogdanfinn/fhttp"
+	"net"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
 )
 
+// CookieClient is a cookie jar safe for concurrent use. It follows RFC 6265
+// for domain and path matching, expiry and deletion, so cookies set for
+// x.com are not sent to twitter.com hosts and the other way round.
+//
+// Cookie.Domain says which hosts a cookie is sent to:
+//   - ".x.com" (leading dot): x.com and all its subdomains
+//   - "x.com": only that host (a host-only cookie)
+//   - "": every host; the client scopes the credentials it adds to the
+//     web host instead, see models.Config.CookieDomain
+//
+// Cookies are keyed by name, domain and path; adding a cookie replaces the
+// one with the same key. Read Cookies directly only while no other
+// goroutine uses the jar; All returns a copy at any time.
 type CookieClient struct {
 	Cookies []http.Cookie
+
+	mu sync.RWMutex
 }
 
 func NewCookieClient() *CookieClient {
 	return &CookieClient{Cookies: []http.Cookie{}}
 }
 
+// GetCookieValue returns the value of the most recently set cookie named
+// name, whatever its domain. Use Value to get the one sent to a given URL.
 func (jar *CookieClient) GetCookieValue(name string) (string, bool) {
-	for _, cookie := range jar.Cookies {
+	jar.mu.RLock()
+	defer jar.mu.RUnlock()
+
+	now := time.Now()
+	for i := len(jar.Cookies) - 1; i >= 0; i-- {
+		cookie := jar.Cookies[i]
+		if cookie.Name == name && !expired(cookie, now) {
+			return cookie.Value, true
+		}
+	}
+	return "", false
+}
+
+// Value returns the value of the cookie named name that is sent to rawURL
+func (jar *CookieClient) Value(rawURL, name string) (string, bool) {
+	for _, cookie := range jar.forURL(rawURL) {
 		if cookie.Name == name {
 			return cookie.Value, true
 		}
@@ -22,64 +60,289 @@ func (jar *CookieClient) GetCookieValue(name string) (string, bool) {
 	return "", false
 }
 
+// All returns a copy of the unexpired cookies in the jar
+func (jar *CookieClient) All() []http.Cookie {
+	jar.mu.RLock()
+	defer jar.mu.RUnlock()
+
+	now := time.Now()
+	cookies := make([]http.Cookie, 0, len(jar.Cookies))
+	for _, cookie := range jar.Cookies {
+		if !expired(cookie, now) {
+			cookies = append(cookies, cookie)
+		}
+	}
+	return cookies
+}
+
+// AddCookies stores cookies as given, see CookieClient for how Domain is
+// read. An expired cookie, or one with a negative MaxAge, deletes the
+// stored cookie with the same name, domain and path.
 func (jar *CookieClient) AddCookies(cookies []http.Cookie) {
+	jar.mu.Lock()
+	defer jar.mu.Unlock()
+
+	now := time.Now()
 	for _, cookie := range cookies {
-		jar.Cookies = append(jar.Cookies, cookie)
+		cookie.Domain = strings.ToLower(cookie.Domain)
+		jar.store(cookie, now)
 	}
 }
 
-func (jar *CookieClient) SetCookieFromResponse(resp *http.Response) {
-	for _, httpCookie := range resp.Cookies() {
-		updated := false
-		newCookie := http.Cookie{
-			Name:  httpCookie.Name,
-			Value: httpCookie.Value,
-		}
+// SetCookies stores cookies received in a response from u, like
+// http.CookieJar. Cookies without a Domain attribute are host-only, and
+// cookies whose domain does not match u's host are rejected.
+func (jar *CookieClient) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	jar.mu.Lock()
+	defer jar.mu.Unlock()
 
-		if httpCookie.Path != "" {
-			newCookie.Path = httpCookie.Path
+	now := time.Now()
+	for _, received := range cookies {
+		if received == nil || received.Name == "" {
+			continue
 		}
-
-		if httpCookie.Domain != "" {
-			newCookie.Domain = httpCookie.Domain
+		cookie := *received
+		if u != nil {
+			host := canonicalHost(u.Host)
+			domain, ok := cookieDomain(host, cookie.Domain)
+			if !ok {
+				continue
+			}
+			cookie.Domain = domain
+			if cookie.Path == "" || !strings.HasPrefix(cookie.Path, "/") {
+				cookie.Path = defaultPath(u.Path)
+			}
+		} else if cookie.Domain != "" {
+			cookie.Domain = "." + strings.TrimPrefix(strings.ToLower(cookie.Domain), ".")
 		}
+		jar.store(cookie, now)
+	}
+}
 
-		if httpCookie.MaxAge > 0 {
-			newCookie.MaxAge = httpCookie.MaxAge
-		}
+// SetCookieFromResponse stores the cookies set by resp, using the request
+// URL recorded in resp for domain matching when there is one
+func (jar *CookieClient) SetCookieFromResponse(resp *http.Response) {
+	var u *url.URL
+	if resp.Request != nil {
+		u = resp.Request.URL
+	}
+	jar.SetCookies(u, resp.Cookies())
+}
+
+// CookiesFor returns the cookies to send to u, most specific first, like
+// http.CookieJar.Cookies
+func (jar *CookieClient) CookiesFor(u *url.URL) []*http.Cookie {
+	matched := jar.matching(u)
+	cookies := make([]*http.Cookie, len(matched))
+	for i := range matched {
+		cookies[i] = &http.Cookie{Name: matched[i].Name, Value: matched[i].Value}
+	}
+	return cookies
+}
+
+// Header returns the Cookie header value to send to rawURL. Only the most
+// specific cookie of each name is sent, since X rejects a request whose ct0
+// cookie is ambiguous.
+func (jar *CookieClient) Header(rawURL string) string {
+	cookies := jar.forURL(rawURL)
+	pairs := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		pairs = append(pairs, cookie.Name+"="+cookie.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// CookiesToHeader this function converts cookies from the []cycletls.Cookie{} format to a header format
+// that simply contains a string of all cookies in the name=value format;
+// It ignores domains and paths, keeping the most recently set cookie of each
+// name; use Header for a request to a known URL.
+func (jar *CookieClient) CookiesToHeader() string {
+	jar.mu.RLock()
+	defer jar.mu.RUnlock()
 
-		if httpCookie.Secure {
-			newCookie.Secure = httpCookie.Secure
+	now := time.Now()
+	seen := make(map[string]bool, len(jar.Cookies))
+	var cookieStrs []string
+	for i := len(jar.Cookies) - 1; i >= 0; i-- {
+		cookie := jar.Cookies[i]
+		if seen[cookie.Name] || expired(cookie, now) {
+			continue
 		}
+		seen[cookie.Name] = true
+		cookieStrs = append(cookieStrs, cookie.Name+"="+cookie.Value)
+	}
+	for i, j := 0, len(cookieStrs)-1; i < j; i, j = i+1, j-1 {
+		cookieStrs[i], cookieStrs[j] = cookieStrs[j], cookieStrs[i]
+	}
+	return strings.Join(cookieStrs, "; ")
+}
 
-		if httpCookie.HttpOnly {
-			newCookie.HttpOnly = httpCookie.HttpOnly
+// Clear removes every cookie from the jar
+func (jar *CookieClient) Clear() {
+	jar.mu.Lock()
+	defer jar.mu.Unlock()
+	jar.Cookies = []http.Cookie{}
+}
+
+// forURL returns the cookies sent to rawURL, one per name. An unparsable
+// URL gets the cookies without a domain.
+func (jar *CookieClient) forURL(rawURL string) []http.Cookie {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		u = &url.URL{}
+	}
+	matched := jar.matching(u)
+
+	seen := make(map[string]bool, len(matched))
+	cookies := matched[:0]
+	for _, cookie := range matched {
+		if !seen[cookie.Name] {
+			seen[cookie.Name] = true
+			cookies = append(cookies, cookie)
 		}
+	}
+	return cookies
+}
 
-		if !httpCookie.Expires.IsZero() {
-			newCookie.Expires = httpCookie.Expires
+// matching returns the unexpired cookies for u, ordered by path length,
+// then domain specificity, then age (RFC 6265 section 5.4)
+func (jar *CookieClient) matching(u *url.URL) []http.Cookie {
+	jar.mu.RLock()
+	defer jar.mu.RUnlock()
+
+	host := canonicalHost(u.Host)
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	secure := u.Scheme == "https"
+
+	now := time.Now()
+	var cookies []http.Cookie
+	for _, cookie := range jar.Cookies {
+		if expired(cookie, now) || (cookie.Secure && !secure) ||
+			!domainMatch(host, cookie.Domain) || !pathMatch(path, cookie.Path) {
+			continue
 		}
+		cookies = append(cookies, cookie)
+	}
 
-		for i, existingCookie := range jar.Cookies {
-			if existingCookie.Name == httpCookie.Name {
-				jar.Cookies[i] = newCookie
-				updated = true
-				break
-			}
+	sort.SliceStable(cookies, func(i, j int) bool {
+		if li, lj := len(cookiePath(cookies[i])), len(cookiePath(cookies[j])); li != lj {
+			return li > lj
 		}
+		return domainRank(cookies[i].Domain) > domainRank(cookies[j].Domain)
+	})
+	return cookies
+}
+
+// store adds or replaces cookie, or deletes the stored one if cookie is
+// expired. Callers hold the write lock.
+func (jar *CookieClient) store(cookie http.Cookie, now time.Time) {
+	cookie.Path = cookiePath(cookie)
+	remove := false
+	switch {
+	case cookie.MaxAge < 0:
+		remove = true
+	case cookie.MaxAge > 0:
+		cookie.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		cookie.MaxAge = 0
+	case !cookie.Expires.IsZero() && !cookie.Expires.After(now):
+		remove = true
+	}
+	cookie.Raw, cookie.RawExpires, cookie.Unparsed = "", "", nil
 
-		if !updated {
-			jar.Cookies = append(jar.Cookies, newCookie)
+	kept := jar.Cookies[:0]
+	for _, existing := range jar.Cookies {
+		if expired(existing, now) ||
+			(existing.Name == cookie.Name && existing.Domain == cookie.Domain && cookiePath(existing) == cookie.Path) {
+			continue
 		}
+		kept = append(kept, existing)
+	}
+	jar.Cookies = kept
+	if !remove {
+		jar.Cookies = append(jar.Cookies, cookie)
 	}
 }
 
-// CookiesToHeader this function converts cookies from the []cycletls.Cookie{} format to a header format
-// that simply contains a string of all cookies in the name=value format;
-func (jar *CookieClient) CookiesToHeader() string {
-	var cookieStrs []string
-	for _, cookie := range jar.Cookies {
-		cookieStrs = append(cookieStrs, cookie.Name+"="+cookie.Value)
+func expired(cookie http.Cookie, now time.Time) bool {
+	return !cookie.Expires.IsZero() && !cookie.Expires.After(now)
+}
+
+func cookiePath(cookie http.Cookie) string {
+	if cookie.Path == "" {
+		return "/"
 	}
-	return strings.Join(cookieStrs, "; ")
+	return cookie.Path
+}
+
+// domainRank orders host-only cookies before domain cookies, longer
+// domains first, and cookies without a domain last
+func domainRank(domain string) int {
+	switch {
+	case domain == "":
+		return 0
+	case strings.HasPrefix(domain, "."):
+		return len(domain)
+	default:
+		return len(domain) + 1
+	}
+}
+
+func canonicalHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// domainMatch reports whether a cookie with domain is sent to host
+func domainMatch(host, domain string) bool {
+	switch {
+	case domain == "":
+		return true
+	case strings.HasPrefix(domain, "."):
+		return host == domain[1:] || strings.HasSuffix(host, domain)
+	default:
+		return host == domain
+	}
+}
+
+// cookieDomain returns the jar domain for a cookie received from host with
+// the given Domain attribute, or false if host may not set it
+func cookieDomain(host, attr string) (string, bool) {
+	attr = strings.ToLower(strings.TrimPrefix(attr, "."))
+	if attr == "" {
+		return host, host != ""
+	}
+	// No public suffix list is bundled; refuse at least top level domains
+	// and domains set from IP addresses
+	if !strings.Contains(attr, ".") || net.ParseIP(host) != nil {
+		return "", false
+	}
+	if host != attr && !strings.HasSuffix(host, "."+attr) {
+		return "", false
+	}
+	return "." + attr, true
+}
+
+// pathMatch implements RFC 6265 section 5.1.4
+func pathMatch(requestPath, cookiePath string) bool {
+	if cookiePath == "" || requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// defaultPath implements RFC 6265 section 5.1.4
+func defaultPath(requestPath string) string {
+	i := strings.LastIndex(requestPath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return requestPath[:i]
 }
@@ -0,0 +1,262 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// CookieFormat identifies a cookie export format, see ParseCookies
+type CookieFormat int
+
+const (
+	CookieFormatUnknown    CookieFormat = iota
+	CookieFormatNetscape                // cookies.txt as written by curl, wget and browser extensions
+	CookieFormatJSON                    // EditThisCookie / Cookie-Editor JSON array
+	CookieFormatPlaywright              // Playwright or Puppeteer storage state ({"cookies": [...]})
+	CookieFormatHeader                  // Cookie header value: "auth_token=...; ct0=..."
+)
+
+// String returns the format name
+func (f CookieFormat) String() string {
+	switch f {
+	case CookieFormatNetscape:
+		return "netscape"
+	case CookieFormatJSON:
+		return "json"
+	case CookieFormatPlaywright:
+		return "playwright"
+	case CookieFormatHeader:
+		return "header"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrNoCookies is returned when an export holds no cookies
+var ErrNoCookies = errors.New("no cookies found")
+
+// DetectCookieFormat guesses the format of a cookie export
+func DetectCookieFormat(data string) CookieFormat {
+	data = strings.TrimSpace(strings.TrimPrefix(data, "\ufeff"))
+	switch {
+	case data == "":
+		return CookieFormatUnknown
+	case strings.HasPrefix(data, "["):
+		return CookieFormatJSON
+	case strings.HasPrefix(data, "{"):
+		return CookieFormatPlaywright
+	case strings.HasPrefix(data, "#") || strings.Contains(data, "\t"):
+		return CookieFormatNetscape
+	case strings.Contains(data, "="):
+		return CookieFormatHeader
+	default:
+		return CookieFormatUnknown
+	}
+}
+
+// ParseCookies parses a cookie export in any supported format, detected
+// with DetectCookieFormat. Attributes present in the export (domain, path,
+// expiry, Secure, HttpOnly, SameSite) are kept.
+//
+// Example:
+//
+//	data, _ := os.ReadFile("cookies.txt")
+//	cookies, err := utils.ParseCookies(string(data))
+//	if err == nil {
+//	    jar.AddCookies(cookies)
+//	}
+func ParseCookies(data string) ([]http.Cookie, error) {
+	switch format := DetectCookieFormat(data); format {
+	case CookieFormatNetscape:
+		return ParseNetscapeCookies(data)
+	case CookieFormatJSON, CookieFormatPlaywright:
+		return ParseJSONCookies([]byte(data))
+	case CookieFormatHeader:
+		return ParseCookieHeader(data)
+	default:
+		return nil, fmt.Errorf("unrecognized cookie format")
+	}
+}
+
+// ParseNetscapeCookies parses a Netscape cookies.txt file. Lines prefixed
+// with "#HttpOnly_" are HttpOnly cookies; other comments are skipped.
+func ParseNetscapeCookies(data string) ([]http.Cookie, error) {
+	var cookies []http.Cookie
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("cookies.txt line %d: expected 7 tab-separated fields, got %d", lineNo, len(fields))
+		}
+		hostOnly := !strings.EqualFold(fields[1], "TRUE") // include-subdomains flag
+		cookie := http.Cookie{
+			Domain:   jarDomain(fields[0], &hostOnly),
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Name:     fields[5],
+			Value:    strings.Join(fields[6:], "\t"),
+			HttpOnly: httpOnly,
+		}
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err != nil {
+			return nil, fmt.Errorf("cookies.txt line %d: invalid expiry %q", lineNo, fields[4])
+		} else if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cookies.txt: %w", err)
+	}
+	if len(cookies) == 0 {
+		return nil, ErrNoCookies
+	}
+	return cookies, nil
+}
+
+// jsonCookie covers the fields written by EditThisCookie, Cookie-Editor,
+// Playwright and Puppeteer
+type jsonCookie struct {
+	Name           string   `json:"name"`
+	Value          string   `json:"value"`
+	Domain         string   `json:"domain"`
+	Path           string   `json:"path"`
+	ExpirationDate *float64 `json:"expirationDate,omitempty"` // EditThisCookie, Cookie-Editor
+	Expires        *float64 `json:"expires,omitempty"`        // Playwright, Puppeteer; -1 for session cookies
+	HostOnly       *bool    `json:"hostOnly,omitempty"`
+	Secure         bool     `json:"secure"`
+	HttpOnly       bool     `json:"httpOnly"`
+	SameSite       string   `json:"sameSite,omitempty"`
+	Session        bool     `json:"session"`
+}
+
+// ParseJSONCookies parses an EditThisCookie or Cookie-Editor JSON array,
+// or a Playwright storage state object with a "cookies" array
+func ParseJSONCookies(data []byte) ([]http.Cookie, error) {
+	var entries []jsonCookie
+	trimmed := strings.TrimSpace(strings.TrimPrefix(string(data), "\ufeff"))
+	if strings.HasPrefix(trimmed, "{") {
+		var state struct {
+			Cookies []jsonCookie `json:"cookies"`
+		}
+		if err := json.Unmarshal([]byte(trimmed), &state); err != nil {
+			return nil, fmt.Errorf("failed to parse storage state: %w", err)
+		}
+		entries = state.Cookies
+	} else if err := json.Unmarshal([]byte(trimmed), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON cookies: %w", err)
+	}
+
+	cookies := make([]http.Cookie, 0, len(entries))
+	for i, entry := range entries {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("JSON cookie %d has no name", i)
+		}
+		cookie := http.Cookie{
+			Name:     entry.Name,
+			Value:    entry.Value,
+			Domain:   jarDomain(entry.Domain, entry.HostOnly),
+			Path:     entry.Path,
+			Secure:   entry.Secure,
+			HttpOnly: entry.HttpOnly,
+			SameSite: parseSameSite(entry.SameSite),
+		}
+		expires := entry.ExpirationDate
+		if expires == nil {
+			expires = entry.Expires
+		}
+		if expires != nil && *expires > 0 && !entry.Session {
+			sec, frac := math.Modf(*expires)
+			cookie.Expires = time.Unix(int64(sec), int64(frac*1e9))
+		}
+		cookies = append(cookies, cookie)
+	}
+	if len(cookies) == 0 {
+		return nil, ErrNoCookies
+	}
+	return cookies, nil
+}
+
+// jarDomain applies an export's host-only flag to domain using the
+// CookieClient convention: a leading dot marks a domain cookie
+func jarDomain(domain string, hostOnly *bool) string {
+	domain = strings.ToLower(domain)
+	if domain == "" || hostOnly == nil {
+		return domain
+	}
+	if *hostOnly {
+		return strings.TrimPrefix(domain, ".")
+	}
+	return "." + strings.TrimPrefix(domain, ".")
+}
+
+func parseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none", "no_restriction":
+		return http.SameSiteNoneMode
+	default:
+		return 0
+	}
+}
+
+func formatSameSite(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "lax"
+	case http.SameSiteStrictMode:
+		return "strict"
+	case http.SameSiteNoneMode:
+		return "no_restriction"
+	default:
+		return ""
+	}
+}
+
+// ParseCookieHeader parses a Cookie header value such as
+// "auth_token=...; ct0=...". A leading "Cookie:" is ignored.
+func ParseCookieHeader(header string) ([]http.Cookie, error) {
+	header = strings.TrimSpace(header)
+	if len(header) >= 7 && strings.EqualFold(header[:7], "cookie:") {
+		header = strings.TrimSpace(header[7:])
+	}
+
+	var cookies []http.Cookie
+	for i, pair := range strings.Split(header, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid cookie pair %d in header", i+1)
+		}
+		cookies = append(cookies, http.Cookie{Name: name, Value: strings.Trim(strings.TrimSpace(value), `"`)})
+	}
+	if len(cookies) == 0 {
+		return nil, ErrNoCookies
+	}
+	return cookies, nil
+}
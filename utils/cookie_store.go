@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// CookieStore persists the contents of a CookieClient, see
+// CookieClient.Load and CookieClient.Save
+type CookieStore interface {
+	// Load returns the stored cookies, or none if nothing was saved yet
+	Load() ([]http.Cookie, error)
+	// Save replaces the stored cookies
+	Save(cookies []http.Cookie) error
+}
+
+// FileCookieStore keeps cookies in a JSON file in the Cookie-Editor
+// format, so the file can also be imported into a browser or read back
+// with ParseJSONCookies. The file is written with 0600 permissions since
+// it holds the account credentials.
+type FileCookieStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewFileCookieStore returns a store backed by the file at path
+func NewFileCookieStore(path string) *FileCookieStore {
+	return &FileCookieStore{Path: path}
+}
+
+// Load reads the file; a missing file holds no cookies
+func (s *FileCookieStore) Load() ([]http.Cookie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookie file: %w", err)
+	}
+
+	cookies, err := ParseJSONCookies(data)
+	if errors.Is(err, ErrNoCookies) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cookie file %s: %w", s.Path, err)
+	}
+	return cookies, nil
+}
+
+// Save writes the file atomically
+func (s *FileCookieStore) Save(cookies []http.Cookie) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := MarshalJSONCookies(cookies)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cookie file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err == nil {
+		_, err = tmp.Write(data)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write cookie file: %w", err)
+	}
+	return nil
+}
+
+// MarshalJSONCookies encodes cookies in the Cookie-Editor JSON format
+// read by ParseJSONCookies
+func MarshalJSONCookies(cookies []http.Cookie) ([]byte, error) {
+	entries := make([]jsonCookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		hostOnly := cookie.Domain != "" && cookie.Domain[0] != '.'
+		entry := jsonCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			HostOnly: &hostOnly,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			SameSite: formatSameSite(cookie.SameSite),
+			Session:  cookie.Expires.IsZero(),
+		}
+		if !cookie.Expires.IsZero() {
+			expires := float64(cookie.Expires.UnixNano()) / 1e9
+			entry.ExpirationDate = &expires
+		}
+		entries = append(entries, entry)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cookies: %w", err)
+	}
+	return data, nil
+}
+
+// Load adds the cookies saved in store to the jar
+func (jar *CookieClient) Load(store CookieStore) error {
+	cookies, err := store.Load()
+	if err != nil {
+		return err
+	}
+	jar.AddCookies(cookies)
+	return nil
+}
+
+// Save writes the unexpired cookies in the jar to store
+func (jar *CookieClient) Save(store CookieStore) error {
+	return store.Save(jar.All())
+}
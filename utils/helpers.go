@@ -15,6 +15,9 @@ package utils
 //	IsNumeric("user123") // returns false
 //	IsNumeric("") // returns false
 func IsNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
 	for _, c := range s {
 		if c < '0' || c > '9' {
 			return false
@@ -1,29 +1,25 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
+	"time"
 
 	http "github.com/bogdanfinn/fhttp"
 	tlsClient "github.com/bogdanfinn/tls-client"
-	"github.com/bogdanfinn/tls-client/profiles"
 )
 
+// CreateHttpClient creates a tls-client HttpClient with the default Chrome profile
 func CreateHttpClient(proxies string) (tlsClient.HttpClient, error) {
-	options := []tlsClient.HttpClientOption{
-		tlsClient.WithClientProfile(profiles.Chrome_133_PSK),
-		tlsClient.WithRandomTLSExtensionOrder(),
-		tlsClient.WithInsecureSkipVerify(),
-		tlsClient.WithTimeoutSeconds(30),
-	}
-	if proxies != "" {
-		options = append(options, tlsClient.WithProxyUrl(fmt.Sprintf("http://%s", proxies)))
-	}
-
-	client, err := tlsClient.NewHttpClient(tlsClient.NewNoopLogger(), options...)
+	client, err := createTLSHttpClient(HttpClientOptions{
+		Proxy:           proxies,
+		Timeout:         30 * time.Second,
+		FollowRedirects: true,
+	})
 	if err != nil {
-		Logger{}.Error("Failed to create Http Client: %s", err)
 		return nil, err
 	}
 
@@ -52,16 +48,49 @@ type RequestConfig struct {
 	URL     string
 	Body    io.Reader
 	Headers []HeaderPair
+
+	// Operation names the endpoint, e.g. "FavoriteTweet" or
+	// "/i/api/1.1/friendships/create.json". See OperationFromURL.
+	Operation string
+
+	// Idempotent marks requests that are safe to send twice. GET and HEAD
+	// requests always are.
+	Idempotent bool
+}
+
+// IsIdempotent reports whether the request can be repeated without side effects
+func (c RequestConfig) IsIdempotent() bool {
+	return c.Idempotent || c.Method == http.MethodGet || c.Method == http.MethodHead
+}
+
+// OperationFromURL derives the endpoint name used to key per-endpoint state:
+// the operation name for GraphQL URLs (".../graphql/<queryID>/<Operation>")
+// and the URL path for everything else.
+func OperationFromURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "graphql" && i+2 < len(segments) {
+			return segments[i+2]
+		}
+	}
+	return parsed.Path
 }
 
-// DefaultConfig returns common Twitter request headers and their order
+// DefaultConfig returns common Twitter request headers and their order.
+// The origin header is https://x.com; models.Config.NewRequest sets it
+// from Config.Hosts instead.
 func DefaultConfig() RequestConfig {
 	return RequestConfig{
 		Headers: []HeaderPair{
 			{Key: "accept", Value: "*/*"},
 			{Key: "accept-encoding", Value: "gzip, deflate, br"},
 			{Key: "content-type", Value: "application/x-www-form-urlencoded"},
-			{Key: "origin", Value: "https://twitter.com"},
+			{Key: "origin", Value: "https://x.com"},
 			{Key: "sec-ch-ua-mobile", Value: "?0"},
 			{Key: "sec-ch-ua-platform", Value: `"Windows"`},
 			{Key: "sec-fetch-dest", Value: "empty"},
@@ -73,8 +102,14 @@ func DefaultConfig() RequestConfig {
 }
 
 // MakeRequest handles HTTP requests with proper header ordering and error handling
-func MakeRequest(client tlsClient.HttpClient, config RequestConfig) ([]byte, *http.Response, error) {
-	req, err := http.NewRequest(config.Method, config.URL, config.Body)
+func MakeRequest(client HttpClient, config RequestConfig) ([]byte, *http.Response, error) {
+	return MakeRequestContext(context.Background(), client, config)
+}
+
+// MakeRequestContext is like MakeRequest but binds the request to ctx,
+// so cancelling ctx aborts the in-flight call and the body read.
+func MakeRequestContext(ctx context.Context, client HttpClient, config RequestConfig) ([]byte, *http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, config.Method, config.URL, config.Body)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to build request: %w", err)
 	}
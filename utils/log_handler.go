@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gookit/color"
+)
+
+// ConsoleHandlerOptions configures NewConsoleHandler
+type ConsoleHandlerOptions struct {
+	Level slog.Leveler // Minimum level, defaults to slog.LevelInfo
+
+	// NoColor disables colors. They are also left out when the writer is
+	// not a terminal, such as a file or a buffer.
+	NoColor bool
+}
+
+// ConsoleHandler is a slog.Handler printing colored, human-readable lines:
+//
+//	15:04:05.000 | SUCCESS | - alice | Successfully liked tweet tweet_id=123
+//
+// The account field, if present, is shown before the message.
+type ConsoleHandler struct {
+	w       io.Writer
+	mu      *sync.Mutex
+	level   slog.Leveler
+	noColor bool
+	attrs   []slog.Attr // Attributes from WithAttrs, keys already qualified
+	group   string      // Prefix for keys added later
+}
+
+// NewConsoleHandler returns a ConsoleHandler writing to w
+func NewConsoleHandler(w io.Writer, opts *ConsoleHandlerOptions) *ConsoleHandler {
+	if opts == nil {
+		opts = &ConsoleHandlerOptions{}
+	}
+	level := opts.Level
+	if level == nil {
+		level = slog.LevelInfo
+	}
+
+	return &ConsoleHandler{
+		w:       w,
+		mu:      &sync.Mutex{},
+		level:   level,
+		noColor: opts.NoColor || !isTerminal(w),
+	}
+}
+
+// isTerminal reports whether w writes to a character device
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := append([]slog.Attr{}, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = appendAttr(attrs, h.group, a)
+		return true
+	})
+
+	var account string
+	var fields strings.Builder
+	for _, a := range attrs {
+		if a.Key == KeyAccount && account == "" {
+			account = a.Value.String()
+			continue
+		}
+		fields.WriteByte(' ')
+		fields.WriteString(a.Key)
+		fields.WriteByte('=')
+		fields.WriteString(quoteValue(a.Value))
+	}
+
+	timestamp := r.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	name := levelName(r.Level)
+
+	var line strings.Builder
+	if h.noColor {
+		line.WriteString(timestamp.Format("15:04:05.000") + " | " + padLevel(name) + " | - ")
+	} else {
+		line.WriteString(color.Sprintf("<fg=cyan;op=bold>%s</><fg=%s;op=bold> | %s | </><fg=white>-</> ",
+			timestamp.Format("15:04:05.000"), levelColor(r.Level), padLevel(name)))
+	}
+	if account != "" {
+		line.WriteString(account + " | ")
+	}
+	line.WriteString(r.Message)
+	line.WriteString(fields.String())
+	line.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line.String())
+	return err
+}
+
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		clone.attrs = appendAttr(clone.attrs, h.group, a)
+	}
+	return &clone
+}
+
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.group = h.group + name + "."
+	return &clone
+}
+
+// appendAttr flattens a into attrs, qualifying keys with group
+func appendAttr(attrs []slog.Attr, group string, a slog.Attr) []slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			attrs = appendAttr(attrs, prefix, ga)
+		}
+		return attrs
+	}
+	a.Key = group + a.Key
+	return append(attrs, a)
+}
+
+// quoteValue formats v, quoting strings that would be ambiguous unquoted
+func quoteValue(v slog.Value) string {
+	s := v.String()
+	if v.Kind() == slog.KindDuration {
+		s = v.Duration().Round(time.Millisecond).String()
+	}
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func padLevel(name string) string {
+	return name + strings.Repeat(" ", 7-len(name))
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "red"
+	case level >= slog.LevelWarn:
+		return "yellow"
+	case level >= LevelSuccess:
+		return "green"
+	case level >= slog.LevelInfo:
+		return "white"
+	default:
+		return "blue"
+	}
+}
+
+// multiHandler fans records out to several handlers
+type multiHandler []slog.Handler
+
+// NewMultiHandler returns a slog.Handler that sends every record to all
+// of handlers, e.g. a ConsoleHandler and a JSON handler writing to a file.
+func NewMultiHandler(handlers ...slog.Handler) slog.Handler {
+	return multiHandler(handlers)
+}
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, len(m))
+	for i, h := range m {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
@@ -1,16 +1,40 @@
 package utils
 
 import (
-	"fmt"
-	"time"
-
-	"github.com/gookit/color"
+	"context"
+	"io"
+	"log/slog"
+	"os"
 )
 
-type Logger struct {
-	level LogLevel
+// Logger is the logging interface used throughout the library.
+//
+// Messages carry structured fields the way log/slog does: alternating keys
+// and values, or slog.Attr values. Implement Logger to route logs to your
+// own stack, or wrap an existing *slog.Logger with NewSlogLogger.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Success(msg string, args ...any)
+	Warning(msg string, args ...any)
+	Error(msg string, args ...any)
+
+	// With returns a Logger that adds args to every message
+	With(args ...any) Logger
 }
 
+// Field keys used by the library
+const (
+	KeyAccount   = "account"
+	KeyOperation = "operation"
+	KeyTweetID   = "tweet_id"
+	KeyUser      = "user"
+	KeyStatus    = "status"
+	KeyLatency   = "latency"
+	KeyAttempt   = "attempt"
+	KeyError     = "error"
+)
+
 // LogLevel defines the verbosity of logging
 type LogLevel int
 
@@ -23,52 +47,134 @@ const (
 	LogLevelDebug                   // Detailed debug information
 )
 
+// LevelSuccess is the slog level of Logger.Success messages. It sits
+// between slog.LevelInfo and slog.LevelWarn, matching LogLevelSuccess.
+const LevelSuccess = slog.Level(2)
+
+// levelOff is above every level the library logs at
+const levelOff = slog.Level(1 << 10)
+
+// SlogLevel returns the minimum slog level enabled by l
+func (l LogLevel) SlogLevel() slog.Level {
+	switch {
+	case l <= LogLevelNone:
+		return levelOff
+	case l == LogLevelError:
+		return slog.LevelError
+	case l == LogLevelWarning:
+		return slog.LevelWarn
+	case l == LogLevelSuccess:
+		return LevelSuccess
+	case l == LogLevelInfo:
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// LogFormat selects how the default logger renders messages
+type LogFormat int
+
+const (
+	LogFormatConsole LogFormat = iota // Colored, human-readable lines
+	LogFormatText                     // slog key=value lines
+	LogFormatJSON                     // One JSON object per line
+)
+
+// LoggerOptions configures NewLoggerWithOptions
+type LoggerOptions struct {
+	Level   LogLevel
+	Format  LogFormat
+	Output  io.Writer // Defaults to os.Stdout
+	NoColor bool      // Disable colors in LogFormatConsole
+}
+
+// NewLogger returns a colored console logger writing to stdout
 func NewLogger(level LogLevel) Logger {
-	return Logger{level: level}
+	return NewLoggerWithOptions(LoggerOptions{Level: level})
 }
 
-func (l Logger) Debug(format string, a ...any) error {
-	if l.level >= LogLevelDebug {
-		color.Printf("<fg=cyan;op=bold>%s</><fg=white></><fg=white></><fg=blue;op=bold> | DEBUG   | </><fg=white>-</> %s\n",
-			time.Now().Format("15:04:05.000"),
-			fmt.Sprintf(format, a...))
+// NewLoggerWithOptions builds the default slog-based logger.
+// To write to several sinks at once, combine handlers with NewMultiHandler
+// and wrap the result with NewSlogLogger instead.
+func NewLoggerWithOptions(opts LoggerOptions) Logger {
+	output := opts.Output
+	if output == nil {
+		output = os.Stdout
 	}
-	return fmt.Errorf(format, a...)
-}
 
-func (l Logger) Info(format string, a ...any) error {
-	if l.level >= LogLevelInfo {
-		color.Printf("<fg=cyan;op=bold>%s</><fg=white></><fg=white></><fg=white;op=bold> | INFO    | </><fg=white>-</> %s\n",
-			time.Now().Format("15:04:05.000"),
-			fmt.Sprintf(format, a...))
+	level := opts.Level.SlogLevel()
+	var handler slog.Handler
+	switch opts.Format {
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(output, &slog.HandlerOptions{Level: level, ReplaceAttr: ReplaceLevelAttr})
+	case LogFormatText:
+		handler = slog.NewTextHandler(output, &slog.HandlerOptions{Level: level, ReplaceAttr: ReplaceLevelAttr})
+	default:
+		handler = NewConsoleHandler(output, &ConsoleHandlerOptions{Level: level, NoColor: opts.NoColor})
 	}
-	return fmt.Errorf(format, a...)
+
+	return NewSlogLogger(slog.New(handler))
 }
 
-func (l Logger) Error(format string, a ...any) error {
-	err := fmt.Errorf(format, a...)
-	if l.level >= LogLevelError {
-		color.Printf("<fg=cyan;op=bold>%s</><fg=white></><fg=white></><fg=red;op=bold> | ERROR   | </><fg=white>-</> %s\n",
-			time.Now().Format("15:04:05.000"),
-			err.Error())
+// ReplaceAttr function for slog.HandlerOptions that names LevelSuccess
+// "SUCCESS" instead of "INFO+2"
+func ReplaceLevelAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.LevelKey {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(levelName(level))
+		}
 	}
-	return err
+	return a
 }
 
-func (l Logger) Success(format string, a ...any) error {
-	if l.level >= LogLevelSuccess {
-		color.Printf("<fg=cyan;op=bold>%s</><fg=white></><fg=white></><fg=green;op=bold> | SUCCESS | </><fg=white>-</> %s\n",
-			time.Now().Format("15:04:05.000"),
-			fmt.Sprintf(format, a...))
+// levelName returns the name the library uses for level
+func levelName(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= LevelSuccess:
+		return "SUCCESS"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	default:
+		return "DEBUG"
 	}
-	return fmt.Errorf(format, a...)
 }
 
-func (l Logger) Warning(format string, a ...any) error {
-	if l.level >= LogLevelWarning {
-		color.Printf("<fg=cyan;op=bold>%s</><fg=white></><fg=white></><fg=yellow;op=bold> | WARNING | </><fg=white>-</> %s\n",
-			time.Now().Format("15:04:05.000"),
-			fmt.Sprintf(format, a...))
-	}
-	return fmt.Errorf(format, a...)
+// slogLogger adapts a *slog.Logger to Logger
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger that writes to logger.
+// Success messages are logged at LevelSuccess.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger: logger}
+}
+
+func (l slogLogger) Debug(msg string, args ...any) {
+	l.logger.Log(context.Background(), slog.LevelDebug, msg, args...)
+}
+
+func (l slogLogger) Info(msg string, args ...any) {
+	l.logger.Log(context.Background(), slog.LevelInfo, msg, args...)
+}
+
+func (l slogLogger) Success(msg string, args ...any) {
+	l.logger.Log(context.Background(), LevelSuccess, msg, args...)
+}
+
+func (l slogLogger) Warning(msg string, args ...any) {
+	l.logger.Log(context.Background(), slog.LevelWarn, msg, args...)
+}
+
+func (l slogLogger) Error(msg string, args ...any) {
+	l.logger.Log(context.Background(), slog.LevelError, msg, args...)
+}
+
+func (l slogLogger) With(args ...any) Logger {
+	return slogLogger{logger: l.logger.With(args...)}
 }
@@ -0,0 +1,189 @@
+package utils_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// decodeLines parses the JSON objects written one per line to buf
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+// logAll logs one message at every level of l
+func logAll(l utils.Logger) {
+	l.Debug("debug")
+	l.Info("info")
+	l.Success("success")
+	l.Warning("warning")
+	l.Error("error")
+}
+
+func TestLogLevelSlogLevel(t *testing.T) {
+	tests := []struct {
+		level utils.LogLevel
+		want  []string // Messages logged at that level
+	}{
+		{utils.LogLevelNone, nil},
+		{utils.LogLevel(-1), nil},
+		{utils.LogLevelError, []string{"error"}},
+		{utils.LogLevelWarning, []string{"warning", "error"}},
+		{utils.LogLevelSuccess, []string{"success", "warning", "error"}},
+		{utils.LogLevelInfo, []string{"info", "success", "warning", "error"}},
+		{utils.LogLevelDebug, []string{"debug", "info", "success", "warning", "error"}},
+		{utils.LogLevel(10), []string{"debug", "info", "success", "warning", "error"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		logAll(utils.NewLoggerWithOptions(utils.LoggerOptions{Level: tt.level, Format: utils.LogFormatJSON, Output: &buf}))
+
+		var got []string
+		for _, entry := range decodeLines(t, &buf) {
+			got = append(got, entry["msg"].(string))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("level %d logged %q, want %q", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSlogLoggerLevels(t *testing.T) {
+	var records []slog.Record
+	handler := &recordingHandler{records: &records}
+	logAll(utils.NewSlogLogger(slog.New(handler)))
+
+	want := []slog.Level{slog.LevelDebug, slog.LevelInfo, utils.LevelSuccess, slog.LevelWarn, slog.LevelError}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, r := range records {
+		if r.Level != want[i] {
+			t.Errorf("%s logged at %v, want %v", r.Message, r.Level, want[i])
+		}
+	}
+	if utils.LevelSuccess <= slog.LevelInfo || utils.LevelSuccess >= slog.LevelWarn {
+		t.Errorf("LevelSuccess = %v, want between INFO and WARN", utils.LevelSuccess)
+	}
+}
+
+func TestJSONLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := utils.NewLoggerWithOptions(utils.LoggerOptions{Level: utils.LogLevelDebug, Format: utils.LogFormatJSON, Output: &buf})
+
+	logger.Success("Liked tweet", utils.KeyTweetID, "123", slog.Int(utils.KeyAttempt, 2))
+	logger.Warning("Rate limited", utils.KeyOperation, "FavoriteTweet")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %s", len(lines), buf.String())
+	}
+	want := []map[string]any{
+		{"level": "SUCCESS", "msg": "Liked tweet", utils.KeyTweetID: "123", utils.KeyAttempt: float64(2)},
+		{"level": "WARNING", "msg": "Rate limited", utils.KeyOperation: "FavoriteTweet"},
+	}
+	for i, fields := range want {
+		for key, value := range fields {
+			if lines[i][key] != value {
+				t.Errorf("line %d %s = %v, want %v", i, key, lines[i][key], value)
+			}
+		}
+		if _, ok := lines[i]["time"]; !ok {
+			t.Errorf("line %d has no time", i)
+		}
+	}
+}
+
+func TestLoggerWith(t *testing.T) {
+	var buf bytes.Buffer
+	base := utils.NewLoggerWithOptions(utils.LoggerOptions{Level: utils.LogLevelInfo, Format: utils.LogFormatJSON, Output: &buf})
+	account := base.With(utils.KeyAccount, "alice")
+	request := account.With(utils.KeyOperation, "FavoriteTweet")
+
+	request.Info("sent", utils.KeyStatus, 200)
+	account.Info("idle")
+	base.Info("bare")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if lines[0][utils.KeyAccount] != "alice" || lines[0][utils.KeyOperation] != "FavoriteTweet" || lines[0][utils.KeyStatus] != float64(200) {
+		t.Errorf("line 0 = %v, want the attributes of both With calls", lines[0])
+	}
+	if lines[1][utils.KeyAccount] != "alice" || lines[1][utils.KeyOperation] != nil {
+		t.Errorf("line 1 = %v, want only the account", lines[1])
+	}
+	if lines[2][utils.KeyAccount] != nil {
+		t.Errorf("line 2 = %v, want no attributes from With", lines[2])
+	}
+}
+
+func TestConsoleHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := utils.NewLoggerWithOptions(utils.LoggerOptions{Level: utils.LogLevelSuccess, Output: &buf}).
+		With(utils.KeyAccount, "alice")
+
+	logger.Info("hidden")
+	logger.Success("Liked tweet", utils.KeyTweetID, "123", "note", "two words")
+
+	line := strings.TrimSpace(buf.String())
+	if strings.Contains(line, "hidden") || strings.Contains(line, "\x1b[") {
+		t.Errorf("line = %q, want only the success message without colors", line)
+	}
+	if want := "| SUCCESS | - alice | Liked tweet tweet_id=123 note=\"two words\""; !strings.HasSuffix(line, want) {
+		t.Errorf("line = %q, want it to end with %q", line, want)
+	}
+}
+
+func TestMultiHandler(t *testing.T) {
+	var console, file bytes.Buffer
+	handler := utils.NewMultiHandler(
+		utils.NewConsoleHandler(&console, &utils.ConsoleHandlerOptions{Level: slog.LevelWarn}),
+		slog.NewJSONHandler(&file, &slog.HandlerOptions{Level: slog.LevelDebug, ReplaceAttr: utils.ReplaceLevelAttr}),
+	)
+	logger := utils.NewSlogLogger(slog.New(handler)).With(utils.KeyAccount, "alice")
+	logger.Debug("details")
+	logger.Error("failed")
+
+	if got := strings.Count(console.String(), "\n"); got != 1 || !strings.Contains(console.String(), "failed") {
+		t.Errorf("console = %q, want only the error", console.String())
+	}
+	lines := decodeLines(t, &file)
+	if len(lines) != 2 || lines[0]["level"] != "DEBUG" || lines[1]["level"] != "ERROR" || lines[1][utils.KeyAccount] != "alice" {
+		t.Errorf("file = %v, want both messages with the account", lines)
+	}
+}
+
+// recordingHandler keeps every record it handles
+type recordingHandler struct {
+	records *[]slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	*h.records = append(*h.records, r)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// Response is a fully read response as seen by middleware
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	// Raw is the response the transport returned, with its body already
+	// consumed. It is nil for responses produced by middleware.
+	Raw *http.Response
+}
+
+// RoundTripper sends a request and returns the fully read response.
+// RequestConfig.Operation names the endpoint being called.
+type RoundTripper interface {
+	RoundTrip(ctx context.Context, req *RequestConfig) (*Response, error)
+}
+
+// RoundTripperFunc adapts a function to the RoundTripper interface
+type RoundTripperFunc func(ctx context.Context, req *RequestConfig) (*Response, error)
+
+func (f RoundTripperFunc) RoundTrip(ctx context.Context, req *RequestConfig) (*Response, error) {
+	return f(ctx, req)
+}
+
+// Middleware wraps a RoundTripper to observe or alter requests and responses.
+//
+// Example:
+//
+//	audit := func(next utils.RoundTripper) utils.RoundTripper {
+//	    return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+//	        resp, err := next.RoundTrip(ctx, req)
+//	        if err == nil {
+//	            log.Printf("%s %s -> %d", req.Method, req.Operation, resp.StatusCode)
+//	        }
+//	        return resp, err
+//	    })
+//	}
+type Middleware func(next RoundTripper) RoundTripper
+
+// NewTransport returns the RoundTripper that sends requests through client
+func NewTransport(client HttpClient) RoundTripper {
+	return RoundTripperFunc(func(ctx context.Context, req *RequestConfig) (*Response, error) {
+		bodyBytes, resp, err := MakeRequestContext(ctx, client, *req)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       bodyBytes,
+			Raw:        resp,
+		}, nil
+	})
+}
+
+// Chain wraps rt with the middlewares. The first middleware is the
+// outermost one: it sees the request first and the response last.
+func Chain(rt RoundTripper, middlewares ...Middleware) RoundTripper {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			rt = middlewares[i](rt)
+		}
+	}
+	return rt
+}
+
+// Send runs a request through rt and returns the result in the same shape
+// as MakeRequest. Responses produced by middleware get a synthetic
+// *http.Response so cookies and headers can be read as usual.
+func Send(ctx context.Context, rt RoundTripper, config RequestConfig) ([]byte, *http.Response, error) {
+	if config.Operation == "" {
+		config.Operation = OperationFromURL(config.URL)
+	}
+
+	resp, err := rt.RoundTrip(ctx, &config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw := resp.Raw
+	if raw == nil {
+		raw = &http.Response{
+			Status:     http.StatusText(resp.StatusCode),
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+		}
+	}
+	if raw.Header == nil {
+		raw.Header = make(http.Header)
+	}
+	raw.Body = io.NopCloser(bytes.NewReader(resp.Body))
+
+	return resp.Body, raw, nil
+}
+
+// SetHeader replaces the value of an existing header, keeping its position,
+// or appends it if the request does not carry it yet
+func (c *RequestConfig) SetHeader(key, value string) {
+	found := false
+	for i := range c.Headers {
+		if strings.EqualFold(c.Headers[i].Key, key) {
+			c.Headers[i].Value = value
+			found = true
+		}
+	}
+	if !found {
+		c.Headers = append(c.Headers, HeaderPair{Key: key, Value: value})
+	}
+}
+
+// DelHeader removes every value of a header. The header list is copied,
+// so copies of c made before the call keep their headers.
+func (c *RequestConfig) DelHeader(key string) {
+	kept := make([]HeaderPair, 0, len(c.Headers))
+	for _, header := range c.Headers {
+		if !strings.EqualFold(header.Key, key) {
+			kept = append(kept, header)
+		}
+	}
+	c.Headers = kept
+}
+
+// GetHeader returns the last value set for a header
+func (c *RequestConfig) GetHeader(key string) string {
+	value := ""
+	for _, header := range c.Headers {
+		if strings.EqualFold(header.Key, key) {
+			value = header.Value
+		}
+	}
+	return value
+}
+
+// BodyBytes reads the request body and puts an identical reader back,
+// so middleware can inspect the body without consuming it
+func (c *RequestConfig) BodyBytes() ([]byte, error) {
+	if c.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(c.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.Body = bytes.NewReader(body)
+	return body, nil
+}
@@ -0,0 +1,107 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/utils"
+	http "github.com/bogdanfinn/fhttp"
+)
+
+// tracing returns a middleware appending "name>" before and "<name" after
+// the rest of the chain to trace
+func tracing(name string, trace *[]string) utils.Middleware {
+	return func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			*trace = append(*trace, name+">")
+			resp, err := next.RoundTrip(ctx, req)
+			*trace = append(*trace, "<"+name)
+			return resp, err
+		})
+	}
+}
+
+// terminal is the innermost RoundTripper, answering 200 with body
+func terminal(body string, trace *[]string) utils.RoundTripper {
+	return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+		*trace = append(*trace, "send "+req.Operation)
+		return &utils.Response{StatusCode: 200, Body: []byte(body)}, nil
+	})
+}
+
+func TestChainOrder(t *testing.T) {
+	var trace []string
+	rt := utils.Chain(terminal("ok", &trace), tracing("first", &trace), nil, tracing("second", &trace))
+
+	body, resp, err := utils.Send(context.Background(), rt, utils.RequestConfig{Method: "GET", URL: "https://x.com/i/api/graphql/abc/Viewer"})
+	if err != nil || string(body) != "ok" || resp.StatusCode != 200 {
+		t.Fatalf("Send() = %q, %v, %v", body, resp, err)
+	}
+	want := "first> second> send Viewer <second <first"
+	if got := strings.Join(trace, " "); got != want {
+		t.Errorf("trace = %q, want %q", got, want)
+	}
+}
+
+func TestChainShortCircuit(t *testing.T) {
+	var trace []string
+	cached := func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			header := http.Header{}
+			header.Add("set-cookie", "ct0=cached; Path=/")
+			return &utils.Response{StatusCode: 202, Header: header, Body: []byte("cached")}, nil
+		})
+	}
+	rt := utils.Chain(terminal("ok", &trace), tracing("outer", &trace), cached, tracing("inner", &trace))
+
+	body, resp, err := utils.Send(context.Background(), rt, utils.RequestConfig{Method: "GET", URL: "https://x.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "cached" || resp.StatusCode != 202 {
+		t.Errorf("Send() = %d %q, want the middleware's response", resp.StatusCode, body)
+	}
+	if cookies := resp.Cookies(); len(cookies) != 1 || cookies[0].Value != "cached" {
+		t.Errorf("Cookies() = %v, want the middleware's cookie", cookies)
+	}
+	if read, _ := io.ReadAll(resp.Body); string(read) != "cached" {
+		t.Errorf("response body = %q, want it readable", read)
+	}
+	if got := strings.Join(trace, " "); got != "outer> <outer" {
+		t.Errorf("trace = %q, want the rest of the chain skipped", got)
+	}
+}
+
+func TestChainErrors(t *testing.T) {
+	var trace []string
+	errDenied := errors.New("denied")
+	deny := func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			return nil, fmt.Errorf("%s: %w", req.Operation, errDenied)
+		})
+	}
+	var seen error
+	observe := func(next utils.RoundTripper) utils.RoundTripper {
+		return utils.RoundTripperFunc(func(ctx context.Context, req *utils.RequestConfig) (*utils.Response, error) {
+			resp, err := next.RoundTrip(ctx, req)
+			seen = err
+			return resp, err
+		})
+	}
+	rt := utils.Chain(terminal("ok", &trace), observe, deny)
+
+	body, resp, err := utils.Send(context.Background(), rt, utils.RequestConfig{Method: "POST", URL: "https://x.com/i/api/graphql/abc/FavoriteTweet"})
+	if !errors.Is(err, errDenied) || body != nil || resp != nil {
+		t.Errorf("Send() = %q, %v, %v, want the middleware's error", body, resp, err)
+	}
+	if !errors.Is(seen, errDenied) || !strings.Contains(seen.Error(), "FavoriteTweet") {
+		t.Errorf("outer middleware saw %v, want the error with the operation", seen)
+	}
+	if len(trace) != 0 {
+		t.Errorf("trace = %q, want nothing sent", trace)
+	}
+}
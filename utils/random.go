@@ -1,27 +1,57 @@
 package utils
 
 import (
+	"context"
 	"math/rand"
 	"time"
 )
 
 // RandomSleep sleeps for a random duration between min and max seconds
 func RandomSleep(min, max int) {
+	_ = RandomSleepContext(context.Background(), min, max)
+}
+
+// RandomSleepContext sleeps for a random duration between min and max seconds.
+// It returns early with the context's error if ctx is done before the sleep ends.
+func RandomSleepContext(ctx context.Context, min, max int) error {
 	if min > max {
 		min, max = max, min // Swap if min is greater than max
 	}
 	if min == max {
-		time.Sleep(time.Duration(min) * time.Second)
-		return
+		return SleepContext(ctx, min)
 	}
 
 	// Create a new random source each time for true randomness
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	seconds := r.Intn(max-min+1) + min
-	time.Sleep(time.Duration(seconds) * time.Second)
+	return SleepContext(ctx, seconds)
 }
 
 // Sleep sleeps for the exact number of seconds
 func Sleep(seconds int) {
 	time.Sleep(time.Duration(seconds) * time.Second)
 }
+
+// SleepContext sleeps for the exact number of seconds or until ctx is done,
+// whichever happens first. It returns the context's error if ctx ended the sleep.
+func SleepContext(ctx context.Context, seconds int) error {
+	return SleepDuration(ctx, time.Duration(seconds)*time.Second)
+}
+
+// SleepDuration sleeps for d or until ctx is done, whichever happens first.
+// It returns the context's error if ctx ended the sleep.
+func SleepDuration(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitRule allows Limit requests per Window.
+// The zero value means no client-side budget.
+type RateLimitRule struct {
+	Limit  int
+	Window time.Duration
+}
+
+func (r RateLimitRule) enabled() bool {
+	return r.Limit > 0 && r.Window > 0
+}
+
+// RateLimiter throttles requests per key (usually an endpoint name).
+// Each key gets a token bucket built from its RateLimitRule, further
+// narrowed by the window the server reports through Observe and by
+// explicit back-offs set with Block. It is safe for concurrent use.
+type RateLimiter struct {
+	// Now returns the current time, time.Now if nil. Set it before the
+	// limiter is used, e.g. to drive it from a fake clock in tests.
+	Now func() time.Time
+
+	mu          sync.Mutex
+	defaultRule RateLimitRule
+	rules       map[string]RateLimitRule
+	buckets     map[string]*rateBucket
+}
+
+type rateBucket struct {
+	rule   RateLimitRule
+	tokens float64
+	last   time.Time
+
+	// Server-reported window, valid while now < reset
+	remaining int
+	reset     time.Time
+
+	blockedUntil time.Time
+}
+
+// NewRateLimiter creates a limiter using defaultRule for keys without their own rule
+func NewRateLimiter(defaultRule RateLimitRule, rules map[string]RateLimitRule) *RateLimiter {
+	copied := make(map[string]RateLimitRule, len(rules))
+	for key, rule := range rules {
+		copied[key] = rule
+	}
+
+	return &RateLimiter{
+		defaultRule: defaultRule,
+		rules:       copied,
+		buckets:     make(map[string]*rateBucket),
+	}
+}
+
+// Reserve takes a request slot for key if one is available and returns 0.
+// Otherwise it takes nothing and returns how long to wait before trying again.
+func (l *RateLimiter) Reserve(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.bucket(key, now)
+
+	var wait time.Duration
+	if now.Before(b.blockedUntil) {
+		wait = b.blockedUntil.Sub(now)
+	}
+
+	windowKnown := now.Before(b.reset)
+	if windowKnown && b.remaining <= 0 {
+		wait = max(wait, b.reset.Sub(now))
+	}
+
+	if b.rule.enabled() {
+		rate := float64(b.rule.Limit) / b.rule.Window.Seconds()
+		b.tokens = min(float64(b.rule.Limit), b.tokens+now.Sub(b.last).Seconds()*rate)
+		b.last = now
+		if b.tokens < 1 {
+			wait = max(wait, time.Duration((1-b.tokens)/rate*float64(time.Second)))
+		}
+	}
+
+	if wait > 0 {
+		return wait
+	}
+
+	if b.rule.enabled() {
+		b.tokens--
+	}
+	if windowKnown {
+		b.remaining--
+	}
+	return 0
+}
+
+// Observe seeds the bucket for key with the window reported by the server:
+// once remaining reaches zero, Reserve waits until reset.
+func (l *RateLimiter) Observe(key string, limit, remaining int, reset time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.bucket(key, now)
+	if reset.IsZero() || !reset.After(now) {
+		return
+	}
+
+	b.remaining = remaining
+	b.reset = reset
+	if b.rule.enabled() && limit > 0 && limit < b.rule.Limit {
+		// The server is stricter than the configured budget
+		b.tokens = min(b.tokens, float64(remaining))
+	}
+}
+
+// Block stops requests for key until the given time
+func (l *RateLimiter) Block(key string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key, l.now())
+	if until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+func (l *RateLimiter) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+// bucket returns the bucket for key, creating it full. Callers hold l.mu.
+func (l *RateLimiter) bucket(key string, now time.Time) *rateBucket {
+	if b, ok := l.buckets[key]; ok {
+		return b
+	}
+
+	rule, ok := l.rules[key]
+	if !ok {
+		rule = l.defaultRule
+	}
+	b := &rateBucket{
+		rule:   rule,
+		tokens: float64(rule.Limit),
+		last:   now,
+	}
+	l.buckets[key] = b
+	return b
+}
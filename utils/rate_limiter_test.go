@@ -0,0 +1,103 @@
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+// newFakeClockLimiter returns a limiter driven by a clock that only moves
+// when the returned function is called
+func newFakeClockLimiter(defaultRule utils.RateLimitRule, rules map[string]utils.RateLimitRule) (*utils.RateLimiter, time.Time, func(time.Duration)) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	limiter := utils.NewRateLimiter(defaultRule, rules)
+	limiter.Now = func() time.Time { return now }
+	return limiter, start, func(d time.Duration) { now = now.Add(d) }
+}
+
+func reserve(t *testing.T, limiter *utils.RateLimiter, key string, want time.Duration) {
+	t.Helper()
+	if got := limiter.Reserve(key); got != want {
+		t.Errorf("Reserve(%s) = %v, want %v", key, got, want)
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	limiter, _, advance := newFakeClockLimiter(utils.RateLimitRule{Limit: 2, Window: time.Second}, nil)
+
+	reserve(t, limiter, "FavoriteTweet", 0)
+	reserve(t, limiter, "FavoriteTweet", 0)
+	reserve(t, limiter, "FavoriteTweet", 500*time.Millisecond)
+
+	// A wait that is reported takes no token
+	advance(250 * time.Millisecond)
+	reserve(t, limiter, "FavoriteTweet", 250*time.Millisecond)
+	advance(250 * time.Millisecond)
+	reserve(t, limiter, "FavoriteTweet", 0)
+
+	// The bucket never holds more than Limit
+	advance(time.Hour)
+	reserve(t, limiter, "FavoriteTweet", 0)
+	reserve(t, limiter, "FavoriteTweet", 0)
+	reserve(t, limiter, "FavoriteTweet", 500*time.Millisecond)
+}
+
+func TestRateLimiterRules(t *testing.T) {
+	limiter, _, _ := newFakeClockLimiter(utils.RateLimitRule{}, map[string]utils.RateLimitRule{
+		"CreateTweet": {Limit: 1, Window: time.Minute},
+	})
+
+	reserve(t, limiter, "CreateTweet", 0)
+	reserve(t, limiter, "CreateTweet", time.Minute)
+	for i := 0; i < 100; i++ {
+		if wait := limiter.Reserve("FavoriteTweet"); wait != 0 {
+			t.Fatalf("Reserve(FavoriteTweet) = %v without a rule, want 0", wait)
+		}
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	limiter, start, advance := newFakeClockLimiter(utils.RateLimitRule{}, nil)
+
+	limiter.Observe("FavoriteTweet", 50, 2, start.Add(time.Minute))
+	reserve(t, limiter, "FavoriteTweet", 0)
+	reserve(t, limiter, "FavoriteTweet", 0)
+	reserve(t, limiter, "FavoriteTweet", time.Minute)
+	advance(45 * time.Second)
+	reserve(t, limiter, "FavoriteTweet", 15*time.Second)
+
+	// The reported window ends at reset
+	advance(15 * time.Second)
+	reserve(t, limiter, "FavoriteTweet", 0)
+
+	// Reset times that already passed say nothing about the next window
+	limiter.Observe("FavoriteTweet", 50, 0, start)
+	limiter.Observe("FavoriteTweet", 50, 0, time.Time{})
+	reserve(t, limiter, "FavoriteTweet", 0)
+}
+
+func TestRateLimiterObserveStricterThanRule(t *testing.T) {
+	limiter, start, _ := newFakeClockLimiter(utils.RateLimitRule{Limit: 100, Window: time.Minute}, nil)
+
+	limiter.Observe("FavoriteTweet", 5, 1, start.Add(10*time.Second))
+	reserve(t, limiter, "FavoriteTweet", 0)
+	reserve(t, limiter, "FavoriteTweet", 10*time.Second)
+}
+
+func TestRateLimiterBlock(t *testing.T) {
+	limiter, start, advance := newFakeClockLimiter(utils.RateLimitRule{}, nil)
+
+	limiter.Block("FavoriteTweet", start.Add(10*time.Second))
+	reserve(t, limiter, "FavoriteTweet", 10*time.Second)
+	reserve(t, limiter, "CreateRetweet", 0)
+
+	// An earlier time does not shorten the block
+	limiter.Block("FavoriteTweet", start.Add(time.Second))
+	advance(4 * time.Second)
+	reserve(t, limiter, "FavoriteTweet", 6*time.Second)
+
+	advance(6 * time.Second)
+	reserve(t, limiter, "FavoriteTweet", 0)
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces scrubbed credentials
+const Redacted = "[REDACTED]"
+
+// minSecretLength keeps short values such as "1" from being scrubbed everywhere
+const minSecretLength = 8
+
+var redactPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	// Header dumps: "Cookie: auth_token=...; ct0=..."
+	{regexp.MustCompile(`(?im)^((?:set-)?cookie|authorization|x-csrf-token)(\s*:\s*).+$`), "${1}${2}" + Redacted},
+	// JSON fields: {"auth_token":"..."}
+	{regexp.MustCompile(`(?i)("(?:auth_token|ct0|auth_multi|_twitter_sess|kdt|twid|cookie|authorization|x-csrf-token|password)"\s*:\s*)"[^"]*"`), `${1}"` + Redacted + `"`},
+	// Cookie and query pairs: auth_token=...; ct0=...
+	{regexp.MustCompile(`(?i)\b(auth_token|ct0|auth_multi|_twitter_sess|kdt|twid)=([^;\s&",]+)`), "${1}=" + Redacted},
+	// Bearer tokens
+	{regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9%._~+/=-]+`), "${1}" + Redacted},
+}
+
+// Redactor scrubs credentials from strings, errors and log lines. It knows
+// the usual cookie, header and token shapes, plus any secret values added
+// with AddSecret. A nil *Redactor leaves everything untouched.
+type Redactor struct {
+	mu      sync.RWMutex
+	secrets []string
+}
+
+// NewRedactor returns a Redactor that also scrubs the given secret values
+func NewRedactor(secrets ...string) *Redactor {
+	r := &Redactor{}
+	r.AddSecret(secrets...)
+	return r
+}
+
+// AddSecret registers values that must never appear in output.
+// Empty and very short values are ignored.
+func (r *Redactor) AddSecret(secrets ...string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, secret := range secrets {
+		if len(secret) < minSecretLength {
+			continue
+		}
+		known := false
+		for _, s := range r.secrets {
+			if s == secret {
+				known = true
+				break
+			}
+		}
+		if !known {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+}
+
+// Redact returns s with credentials replaced by Redacted
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+
+	r.mu.RLock()
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	r.mu.RUnlock()
+
+	for _, p := range redactPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// RedactError returns err with a scrubbed message. The original error is
+// still reachable through errors.Is and errors.As.
+func (r *Redactor) RedactError(err error) error {
+	if r == nil || err == nil {
+		return err
+	}
+
+	msg := err.Error()
+	redacted := r.Redact(msg)
+	if redacted == msg {
+		return err
+	}
+	return &redactedError{err: err, msg: redacted}
+}
+
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactLogger scrubs messages and field values before passing them on
+type redactLogger struct {
+	logger   Logger
+	redactor *Redactor
+}
+
+// RedactLogger wraps logger so every message and field value goes through
+// redactor. It returns logger unchanged if redactor is nil.
+func RedactLogger(logger Logger, redactor *Redactor) Logger {
+	if redactor == nil {
+		return logger
+	}
+	if rl, ok := logger.(redactLogger); ok && rl.redactor == redactor {
+		return logger
+	}
+	return redactLogger{logger: logger, redactor: redactor}
+}
+
+func (l redactLogger) Debug(msg string, args ...any) {
+	l.logger.Debug(l.redactor.Redact(msg), l.redactArgs(args)...)
+}
+
+func (l redactLogger) Info(msg string, args ...any) {
+	l.logger.Info(l.redactor.Redact(msg), l.redactArgs(args)...)
+}
+
+func (l redactLogger) Success(msg string, args ...any) {
+	l.logger.Success(l.redactor.Redact(msg), l.redactArgs(args)...)
+}
+
+func (l redactLogger) Warning(msg string, args ...any) {
+	l.logger.Warning(l.redactor.Redact(msg), l.redactArgs(args)...)
+}
+
+func (l redactLogger) Error(msg string, args ...any) {
+	l.logger.Error(l.redactor.Redact(msg), l.redactArgs(args)...)
+}
+
+func (l redactLogger) With(args ...any) Logger {
+	return redactLogger{logger: l.logger.With(l.redactArgs(args)...), redactor: l.redactor}
+}
+
+// redactArgs scrubs string-like values, leaving keys and other types as is
+func (l redactLogger) redactArgs(args []any) []any {
+	out := make([]any, len(args))
+	for i, arg := range args {
+		out[i] = l.redactValue(arg)
+	}
+	return out
+}
+
+func (l redactLogger) redactValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return l.redactor.Redact(v)
+	case []byte:
+		return l.redactor.Redact(string(v))
+	case error:
+		return l.redactor.RedactError(v)
+	case slog.Attr:
+		return l.redactAttr(v)
+	}
+	return v
+}
+
+func (l redactLogger) redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(l.redactor.Redact(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = l.redactAttr(ga)
+		}
+		a.Value = slog.GroupValue(attrs...)
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.AnyValue(l.redactor.RedactError(err))
+		}
+	}
+	return a
+}
@@ -0,0 +1,119 @@
+package utils_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Tootoohk/TwitterAPI/utils"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name, in string
+		secrets  []string // Must not survive
+		keep     []string // Must survive
+	}{
+		{"cookie pairs", "auth_token=" + testAuthToken + "; ct0=" + testCt0 + "; lang=en",
+			[]string{testAuthToken, testCt0}, []string{"auth_token=", "ct0=", "lang=en"}},
+		{"query pairs", "https://x.com/?ct0=" + testCt0 + "&count=20",
+			[]string{testCt0}, []string{"count=20"}},
+		{"cookie header", "GET / HTTP/1.1\nCookie: lang=en; guest_id=v1%3A123\nAccept: */*",
+			[]string{"guest_id=v1%3A123"}, []string{"Cookie: " + utils.Redacted, "Accept: */*"}},
+		{"set-cookie header", "HTTP/1.1 200 OK\nSet-Cookie: session=abcdefgh12345678; Path=/\nContent-Type: application/json",
+			[]string{"abcdefgh12345678"}, []string{"Set-Cookie: " + utils.Redacted, "Content-Type: application/json"}},
+		{"authorization header", "authorization: " + testBearer,
+			[]string{"AAAAAAAAAAAAAAAAAAAAAtestbearertoken"}, []string{"authorization: " + utils.Redacted}},
+		{"bearer token", "sent with Bearer AAAAAAAAAAAAAAAAAAAAAtestbearertoken%3D to x.com",
+			[]string{"AAAAAAAAAAAAAAAAAAAAAtestbearertoken"}, []string{"Bearer " + utils.Redacted, "to x.com"}},
+		{"JSON fields", `{"auth_token":"` + testAuthToken + `", "ct0" : "` + testCt0 + `","password":"hunter2hunter2","screen_name":"alice"}`,
+			[]string{testAuthToken, testCt0, "hunter2hunter2"}, []string{`"auth_token":"` + utils.Redacted + `"`, `"screen_name":"alice"`}},
+		{"JSON cookie and csrf fields", `{"cookie":"auth_token=x","x-csrf-token":"` + testCt0 + `"}`,
+			[]string{testCt0, "auth_token=x"}, []string{`"cookie":"` + utils.Redacted + `"`}},
+		{"nothing to redact", "Successfully liked tweet 1234567890",
+			nil, []string{"Successfully liked tweet 1234567890"}},
+	}
+	redactor := utils.NewRedactor()
+	for _, tt := range tests {
+		got := redactor.Redact(tt.in)
+		for _, secret := range tt.secrets {
+			if strings.Contains(got, secret) {
+				t.Errorf("%s: Redact() = %q, still contains %q", tt.name, got, secret)
+			}
+		}
+		for _, keep := range tt.keep {
+			if !strings.Contains(got, keep) {
+				t.Errorf("%s: Redact() = %q, want it to contain %q", tt.name, got, keep)
+			}
+		}
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	redactor := utils.NewRedactor(testAuthToken, "short", "")
+	redactor.AddSecret(testCt0, testCt0)
+
+	got := redactor.Redact("token " + testAuthToken + " csrf " + testCt0 + " short")
+	if want := "token " + utils.Redacted + " csrf " + utils.Redacted + " short"; got != want {
+		t.Errorf("Redact() = %q, want %q", got, want)
+	}
+
+	var none *utils.Redactor
+	none.AddSecret(testAuthToken)
+	if got := none.Redact("auth_token=" + testAuthToken); got != "auth_token="+testAuthToken {
+		t.Errorf("nil Redactor changed %q", got)
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	redactor := utils.NewRedactor()
+	errSentinel := errors.New("request failed")
+	err := fmt.Errorf("%w: unexpected response: {\"auth_token\":\"%s\"}", errSentinel, testAuthToken)
+
+	redacted := redactor.RedactError(err)
+	if strings.Contains(redacted.Error(), testAuthToken) || !strings.Contains(redacted.Error(), "unexpected response:") {
+		t.Errorf("RedactError() = %q", redacted)
+	}
+	if !errors.Is(redacted, errSentinel) {
+		t.Error("RedactError() hides the wrapped error from errors.Is")
+	}
+
+	clean := errors.New("timeout")
+	if redactor.RedactError(clean) != clean || redactor.RedactError(nil) != nil {
+		t.Error("RedactError() replaced an error without secrets")
+	}
+}
+
+func TestRedactLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := utils.NewLoggerWithOptions(utils.LoggerOptions{Level: utils.LogLevelDebug, Format: utils.LogFormatJSON, Output: &buf})
+	logger := utils.RedactLogger(base, utils.NewRedactor(testAuthToken))
+
+	logger.With("cookie", "ct0="+testCt0).Debug("sending with auth_token="+testAuthToken,
+		"header", []byte("Authorization: "+testBearer),
+		utils.KeyError, fmt.Errorf(`unexpected response: {"ct0":"%s"}`, testCt0),
+		slog.Group("request", slog.String("url", "https://x.com/?ct0="+testCt0), slog.Any("err", errors.New(testAuthToken))),
+		utils.KeyStatus, 200,
+	)
+
+	out := buf.String()
+	for _, secret := range []string{testAuthToken, testCt0, "testbearertoken"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log line %s contains %q", out, secret)
+		}
+	}
+	lines := decodeLines(t, &buf)
+	if len(lines) != 1 || lines[0][utils.KeyStatus] != float64(200) {
+		t.Errorf("log lines = %v, want non-secret fields kept", lines)
+	}
+
+	if utils.RedactLogger(base, nil) != base {
+		t.Error("RedactLogger(nil) wrapped the logger")
+	}
+	if utils.RedactLogger(logger, nil) != logger {
+		t.Error("RedactLogger(nil) wrapped a redacting logger")
+	}
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/url"
+	"time"
+
+	http "github.com/bogdanfinn/fhttp"
+	tlsClient "github.com/bogdanfinn/tls-client"
+	"github.com/bogdanfinn/tls-client/profiles"
+)
+
+// HttpClient is the transport MakeRequest sends requests through.
+// The tls-client HttpClient satisfies it as is; plain net/http clients
+// can be plugged in with NewStdHttpClient.
+type HttpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TransportType selects which HttpClient implementation NewHttpClient builds
+type TransportType int
+
+const (
+	TransportTLS TransportType = iota // bogdanfinn tls-client with a Chrome fingerprint
+	TransportStd                      // Go standard library net/http
+)
+
+// HttpClientOptions contains the settings used by NewHttpClient
+type HttpClientOptions struct {
+	Transport       TransportType
+	Proxy           string // Format: "ip:port" or "user:pass@ip:port"
+	Timeout         time.Duration
+	FollowRedirects bool
+
+	// InsecureSkipVerify disables certificate checks for TransportStd.
+	// Only enable it for a local stand-in with a self-signed certificate.
+	// TransportTLS does not verify certificates either way.
+	InsecureSkipVerify bool
+}
+
+// NewHttpClient builds an HttpClient for the requested transport.
+//
+// Example:
+//
+//	client, err := NewHttpClient(HttpClientOptions{
+//	    Transport:       TransportStd,
+//	    Timeout:         30 * time.Second,
+//	    FollowRedirects: true,
+//	})
+func NewHttpClient(opts HttpClientOptions) (HttpClient, error) {
+	switch opts.Transport {
+	case TransportTLS:
+		return createTLSHttpClient(opts)
+	case TransportStd:
+		return createStdHttpClient(opts)
+	default:
+		return nil, fmt.Errorf("unknown transport type: %d", opts.Transport)
+	}
+}
+
+func createTLSHttpClient(opts HttpClientOptions) (tlsClient.HttpClient, error) {
+	options := []tlsClient.HttpClientOption{
+		tlsClient.WithClientProfile(profiles.Chrome_133_PSK),
+		tlsClient.WithRandomTLSExtensionOrder(),
+		tlsClient.WithInsecureSkipVerify(),
+		tlsClient.WithTimeoutSeconds(timeoutSeconds(opts.Timeout)),
+	}
+	if opts.Proxy != "" {
+		options = append(options, tlsClient.WithProxyUrl(fmt.Sprintf("http://%s", opts.Proxy)))
+	}
+	if !opts.FollowRedirects {
+		options = append(options, tlsClient.WithNotFollowRedirects())
+	}
+
+	client, err := tlsClient.NewHttpClient(tlsClient.NewNoopLogger(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tls client: %w", err)
+	}
+	return client, nil
+}
+
+func createStdHttpClient(opts HttpClientOptions) (HttpClient, error) {
+	transport := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+	if opts.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(fmt.Sprintf("http://%s", opts.Proxy))
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		transport.Proxy = nethttp.ProxyURL(proxyURL)
+	}
+
+	client := &nethttp.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+	}
+	if !opts.FollowRedirects {
+		client.CheckRedirect = func(*nethttp.Request, []*nethttp.Request) error {
+			return nethttp.ErrUseLastResponse
+		}
+	}
+	return NewStdHttpClient(client), nil
+}
+
+func timeoutSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 30
+	}
+	return int(d.Round(time.Second) / time.Second)
+}
+
+// stdHttpClient adapts a net/http client to the HttpClient interface
+type stdHttpClient struct {
+	client *nethttp.Client
+}
+
+// NewStdHttpClient wraps a standard library client so it can be used as an HttpClient.
+// This is the way to point the library at an httptest server or a recording transport.
+//
+// Header ordering hints are dropped, and so is accept-encoding: net/http only
+// decompresses responses transparently when it negotiates the encoding itself.
+func NewStdHttpClient(client *nethttp.Client) HttpClient {
+	if client == nil {
+		client = nethttp.DefaultClient
+	}
+	return &stdHttpClient{client: client}
+}
+
+func (c *stdHttpClient) Do(req *http.Request) (*http.Response, error) {
+	if req == nil || req.URL == nil {
+		return nil, errors.New("nil request")
+	}
+
+	stdReq, err := nethttp.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), req.Body)
+	if err != nil {
+		return nil, err
+	}
+	stdReq.ContentLength = req.ContentLength
+	for key, values := range req.Header {
+		switch key {
+		case http.HeaderOrderKey, http.PHeaderOrderKey, "Accept-Encoding":
+			continue
+		}
+		stdReq.Header[key] = values
+	}
+	if req.Host != "" {
+		stdReq.Host = req.Host
+	}
+
+	stdResp, err := c.client.Do(stdReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        stdResp.Status,
+		StatusCode:    stdResp.StatusCode,
+		Proto:         stdResp.Proto,
+		ProtoMajor:    stdResp.ProtoMajor,
+		ProtoMinor:    stdResp.ProtoMinor,
+		Header:        http.Header(stdResp.Header),
+		Body:          stdResp.Body,
+		ContentLength: stdResp.ContentLength,
+		Uncompressed:  stdResp.Uncompressed,
+		Request:       req,
+	}, nil
+}